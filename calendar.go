@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	mapset "github.com/deckarep/golang-set/v2"
+)
+
+// parseWeekdays parses a comma-separated list of weekdays like "Fri,Sat", both short and full names are accepted
+func parseWeekdays(value string) (mapset.Set[time.Weekday], error) {
+	weekdays := mapset.NewThreadUnsafeSet[time.Weekday]()
+
+	if strings.TrimSpace(value) == "" {
+		return weekdays, nil
+	}
+
+	for _, part := range strings.Split(value, ",") {
+		weekday, err := parseWeekday(strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+
+		weekdays.Add(weekday)
+	}
+
+	return weekdays, nil
+}
+
+func parseWeekday(value string) (time.Weekday, error) {
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		name := weekday.String()
+
+		if strings.EqualFold(value, name) || strings.EqualFold(value, name[:3]) {
+			return weekday, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown weekday %q", value)
+}
+
+// loadDates reads a file with one date formatted as 2006-01-02 per line, empty lines and lines starting with # are ignored
+func loadDates(fileName string) (mapset.Set[string], error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("opening file: %w", err)
+	}
+	defer file.Close()
+
+	dates := mapset.NewThreadUnsafeSet[string]()
+
+	scanner := bufio.NewScanner(file)
+	lineNumber := 0
+
+	for scanner.Scan() {
+		lineNumber++
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if _, err := time.Parse("2006-01-02", line); err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+
+		dates.Add(line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading file: %w", err)
+	}
+
+	return dates, nil
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/florianloch/days-in-office/office"
+)
+
+// command is a subcommand, the first argument selects it
+type command struct {
+	Name        string
+	Description string
+}
+
+var commands = []command{
+	{Name: "count", Description: "Count the days in the office (default)"},
+	{Name: "validate", Description: "Check that all input files parse cleanly and report the ones that do not"},
+	{Name: "dump", Description: "Write all parsed points as JSON, or the matching visits as GeoJSON with -format geojson"},
+}
+
+// parseCommand splits off the subcommand from the arguments. Without one, count is used so that invocations with
+// bare flags keep working.
+func parseCommand(args []string) (string, []string) {
+	if len(args) > 0 {
+		for _, c := range commands {
+			if args[0] == c.Name {
+				return c.Name, args[1:]
+			}
+		}
+	}
+
+	return "count", args
+}
+
+func usage() {
+	w := flag.CommandLine.Output()
+
+	fmt.Fprintf(w, "Usage: %s [command] [flags]\n\nCommands:\n", filepath.Base(os.Args[0]))
+
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-10s%s\n", c.Name, c.Description)
+	}
+
+	fmt.Fprintf(w, "\nFlags:\n")
+	flag.PrintDefaults()
+}
+
+// noMatcher never matches, it is used if only the input is parsed and no location is given
+type noMatcher struct{}
+
+func (noMatcher) Matches(office.TimelinePoint) bool {
+	return false
+}
+
+// printValidation lists the files with issues and a summary, it returns the number of files with issues
+func printValidation(w io.Writer, stats office.Stats) int {
+	skipped := stats.Skipped
+
+	sort.Slice(skipped, func(i, j int) bool {
+		return skipped[i].Name < skipped[j].Name
+	})
+
+	for _, file := range skipped {
+		fmt.Fprintf(w, "%s: %s: %v\n", file.Name, file.Reason, file.Err)
+	}
+
+	parsed := 0
+
+	for _, count := range stats.Formats {
+		parsed += count
+	}
+
+	fmt.Fprintf(w, "Checked %d file(s): %d parsed cleanly with %d visit(s), %d with issues\n", parsed+len(skipped), parsed, stats.Visits, len(skipped))
+
+	return len(skipped)
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/florianloch/days-in-office/office"
+	"github.com/paulmach/orb"
+)
+
+// config is the structure of the file passed via -config
+type config struct {
+	Locations []configLocation `json:"locations"`
+}
+
+type configLocation struct {
+	Label     string  `json:"label"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	// Tolerance is optional, the global tolerance is used if it is not set
+	Tolerance *float64 `json:"tolerance"`
+}
+
+// loadConfig reads the config file and returns the locations defined in it, locations without a tolerance get the
+// default tolerance
+func loadConfig(fileName string, defaultTolerance float64) ([]office.Location, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("opening config: %w", err)
+	}
+	defer file.Close()
+
+	var c config
+
+	decoder := json.NewDecoder(file)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(&c); err != nil {
+		return nil, fmt.Errorf("decoding config: %w", err)
+	}
+
+	locations := make([]office.Location, 0, len(c.Locations))
+
+	for i, entry := range c.Locations {
+		loc := office.Location{
+			Point:     orb.Point{entry.Longitude, entry.Latitude},
+			Tolerance: defaultTolerance,
+			Label:     entry.Label,
+		}
+
+		if entry.Tolerance != nil {
+			loc.Tolerance = *entry.Tolerance
+		}
+
+		if err := loc.Validate(); err != nil {
+			return nil, fmt.Errorf("location #%d (%q) is invalid: %w", i+1, entry.Label, err)
+		}
+
+		locations = append(locations, loc)
+	}
+
+	return locations, nil
+}
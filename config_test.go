@@ -0,0 +1,69 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/florianloch/days-in-office/office"
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/geo"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+
+	fileName := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(fileName, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	return fileName
+}
+
+func TestLoadConfigTolerances(t *testing.T) {
+	fileName := writeConfig(t, `{"locations": [
+		{"label": "Downtown", "latitude": 52.370216, "longitude": 4.895168, "tolerance": 300},
+		{"label": "Campus", "latitude": 52.3378, "longitude": 4.8652, "tolerance": 1500},
+		{"label": "Satellite", "latitude": 51.9244, "longitude": 4.4777}
+	]}`)
+
+	locations, err := loadConfig(fileName, 750)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []office.Location{
+		{Point: orb.Point{4.895168, 52.370216}, Tolerance: 300, Label: "Downtown"},
+		{Point: orb.Point{4.8652, 52.3378}, Tolerance: 1500, Label: "Campus"},
+		{Point: orb.Point{4.4777, 51.9244}, Tolerance: 750, Label: "Satellite"},
+	}
+
+	if !reflect.DeepEqual(locations, want) {
+		t.Fatalf("got %+v, want %+v", locations, want)
+	}
+
+	// Each location is matched with its own radius, 500m away is only within the campus and the satellite
+	matcher := office.RadiusMatcher(locations)
+
+	for _, loc := range locations {
+		p := geo.PointAtBearingAndDistance(loc.Point, 0, 500)
+		point := office.TimelinePoint{Latitude: p.Lat(), Longitude: p.Lon()}
+
+		got := matcher.Matching(point)
+		wantMatch := loc.Label != "Downtown"
+
+		if wantMatch != (len(got) == 1 && got[0].Label == loc.Label) {
+			t.Errorf("500m north of %s: got matching locations %v, want match %t", loc.Label, got, wantMatch)
+		}
+	}
+}
+
+func TestLoadConfigInvalidTolerance(t *testing.T) {
+	fileName := writeConfig(t, `{"locations": [{"label": "HQ", "latitude": 52.370216, "longitude": 4.895168, "tolerance": 0}]}`)
+
+	if _, err := loadConfig(fileName, 750); err == nil {
+		t.Error("expected an error for a tolerance of 0, even though the default tolerance is valid")
+	}
+}
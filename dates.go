@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	mapset "github.com/deckarep/golang-set/v2"
+)
+
+// parseDate parses either a relative date like "30d ago", a RFC3339 timestamp or a bare date like 2020-01-01.
+// A relative date or bare date is interpreted as the start of the period, or the end of the period if endOfDay is
+// set, so that the last day of a range is included entirely.
+func parseDate(value string, loc *time.Location, endOfDay bool) (time.Time, error) {
+	start, end, ok, err := parseRelativeDate(value, time.Now().In(loc))
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if ok {
+		if endOfDay {
+			return end, nil
+		}
+
+		return start, nil
+	}
+
+	t, err := time.ParseInLocation(time.RFC3339, value, loc)
+	if err == nil {
+		return t.In(loc), nil
+	}
+
+	date, dateErr := time.ParseInLocation("2006-01-02", value, loc)
+	if dateErr != nil {
+		return time.Time{}, fmt.Errorf("expected RFC3339 timestamp, date like 2006-01-02 or relative date like today, yesterday, 30d ago, last-month or this-year, got %q", value)
+	}
+
+	if endOfDay {
+		return lastInstantOfDay(date), nil
+	}
+
+	return date, nil
+}
+
+// parseEndDate parses the end of the time range. Dates without time include the whole day, inclusive extends
+// timestamps to the end of their day as well.
+func parseEndDate(value string, loc *time.Location, inclusive bool) (time.Time, error) {
+	end, err := parseDate(value, loc, true)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if inclusive {
+		return lastInstantOfDay(end), nil
+	}
+
+	return end, nil
+}
+
+// parseRelativeDate returns the first and last instant of the period described by a relative date like "30d ago",
+// ok is false if value is not a relative date
+func parseRelativeDate(value string, now time.Time) (start, end time.Time, ok bool, err error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	switch value = strings.ToLower(strings.TrimSpace(value)); value {
+	case "today":
+		return today, lastInstantOfDay(today), true, nil
+	case "yesterday":
+		yesterday := today.AddDate(0, 0, -1)
+
+		return yesterday, lastInstantOfDay(yesterday), true, nil
+	case "last-month":
+		firstOfMonth := today.AddDate(0, 0, 1-today.Day())
+
+		return firstOfMonth.AddDate(0, -1, 0), firstOfMonth.Add(-time.Nanosecond), true, nil
+	case "this-year":
+		firstOfYear := time.Date(today.Year(), time.January, 1, 0, 0, 0, 0, today.Location())
+
+		return firstOfYear, firstOfYear.AddDate(1, 0, 0).Add(-time.Nanosecond), true, nil
+	}
+
+	days, found := strings.CutSuffix(value, "d ago")
+	if !found {
+		return time.Time{}, time.Time{}, false, nil
+	}
+
+	n, err := strconv.Atoi(strings.TrimSpace(days))
+	if err != nil || n < 0 {
+		return time.Time{}, time.Time{}, false, fmt.Errorf("expected a non-negative number of days like \"30d ago\", got %q", value)
+	}
+
+	day := today.AddDate(0, 0, -n)
+
+	return day, lastInstantOfDay(day), true, nil
+}
+
+// lastInstantOfDay returns the last nanosecond of the day of t
+func lastInstantOfDay(t time.Time) time.Time {
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+
+	return midnight.AddDate(0, 0, 1).Add(-time.Nanosecond)
+}
+
+// parseDateList parses a comma-separated list of dates formatted as 2006-01-02
+func parseDateList(value string) (mapset.Set[string], error) {
+	dates := mapset.NewThreadUnsafeSet[string]()
+
+	if strings.TrimSpace(value) == "" {
+		return dates, nil
+	}
+
+	for _, part := range strings.Split(value, ",") {
+		date := strings.TrimSpace(part)
+
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return nil, fmt.Errorf("expected date like 2006-01-02, got %q", date)
+		}
+
+		dates.Add(date)
+	}
+
+	return dates, nil
+}
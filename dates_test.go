@@ -0,0 +1,135 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	amsterdam, err := time.LoadLocation("Europe/Amsterdam")
+	if err != nil {
+		t.Skipf("timezone database not available: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		value    string
+		endOfDay bool
+		want     time.Time
+		wantErr  bool
+	}{
+		{
+			name:  "bare date as start",
+			value: "2024-03-01",
+			want:  time.Date(2024, 3, 1, 0, 0, 0, 0, amsterdam),
+		},
+		{
+			name:     "bare date as end includes the whole day",
+			value:    "2024-03-31",
+			endOfDay: true,
+			want:     time.Date(2024, 3, 31, 23, 59, 59, 999999999, amsterdam),
+		},
+		{
+			name:  "full timestamp",
+			value: "2024-03-01T08:30:00Z",
+			want:  time.Date(2024, 3, 1, 8, 30, 0, 0, time.UTC),
+		},
+		{
+			name:     "full timestamp as end is taken as-is",
+			value:    "2024-03-31T00:00:00Z",
+			endOfDay: true,
+			want:     time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "full timestamp with offset",
+			value: "2024-03-01T08:30:00+02:00",
+			want:  time.Date(2024, 3, 1, 6, 30, 0, 0, time.UTC),
+		},
+		{
+			name:    "timestamp without offset",
+			value:   "2024-03-01T08:30:00",
+			wantErr: true,
+		},
+		{
+			name:    "invalid date",
+			value:   "2024-02-30",
+			wantErr: true,
+		},
+		{
+			name:    "garbage",
+			value:   "next tuesday",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDate(tt.value, amsterdam, tt.endOfDay)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %s", got)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !got.Equal(tt.want) {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+
+			if got.Location() != amsterdam {
+				t.Errorf("got location %s, want %s", got.Location(), amsterdam)
+			}
+		})
+	}
+}
+
+func TestParseEndDate(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     string
+		inclusive bool
+		want      time.Time
+	}{
+		{
+			name:  "bare date",
+			value: "2024-03-31",
+			want:  time.Date(2024, 3, 31, 23, 59, 59, 999999999, time.UTC),
+		},
+		{
+			name:      "bare date with -inclusive-end",
+			value:     "2024-03-31",
+			inclusive: true,
+			want:      time.Date(2024, 3, 31, 23, 59, 59, 999999999, time.UTC),
+		},
+		{
+			name:  "full timestamp",
+			value: "2024-03-31T00:00:00Z",
+			want:  time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "full timestamp with -inclusive-end",
+			value:     "2024-03-31T00:00:00Z",
+			inclusive: true,
+			want:      time.Date(2024, 3, 31, 23, 59, 59, 999999999, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseEndDate(tt.value, time.UTC, tt.inclusive)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !got.Equal(tt.want) {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
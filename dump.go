@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/florianloch/days-in-office/office"
+)
+
+// normalizedPoint is a point of the timeline as written by -dump-normalized, independent of the input format
+type normalizedPoint struct {
+	Latitude  float64   `json:"latitude"`
+	Longitude float64   `json:"longitude"`
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+}
+
+// pointDumper streams the points of all inputs as a single JSON array, so the points never have to be held in memory.
+// Inputs are processed concurrently, so writes are serialized.
+type pointDumper struct {
+	mutex sync.Mutex
+	w     io.Writer
+	count int
+	err   error
+}
+
+func newPointDumper(w io.Writer) *pointDumper {
+	d := &pointDumper{
+		w: w,
+	}
+
+	_, d.err = io.WriteString(w, "[")
+
+	return d
+}
+
+// Dump writes the points, it is meant to be used as office.Options.Parsed
+func (d *pointDumper) Dump(_ string, points []office.TimelinePoint) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	for _, point := range points {
+		if d.err != nil {
+			return
+		}
+
+		separator := ",\n"
+		if d.count == 0 {
+			separator = "\n"
+		}
+
+		encoded, err := json.Marshal(normalizedPoint{
+			Latitude:  point.Latitude,
+			Longitude: point.Longitude,
+			Start:     point.Start,
+			End:       point.End,
+		})
+		if err != nil {
+			d.err = fmt.Errorf("encoding point: %w", err)
+			return
+		}
+
+		if _, err := fmt.Fprintf(d.w, "%s  %s", separator, encoded); err != nil {
+			d.err = err
+			return
+		}
+
+		d.count++
+	}
+}
+
+// Close terminates the array and returns the first error that occurred while writing
+func (d *pointDumper) Close() error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.err == nil {
+		_, d.err = io.WriteString(d.w, "\n]\n")
+	}
+
+	return d.err
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/geojson"
+)
+
+// loadGeofence reads a GeoJSON file and collects all polygons contained in it.
+// The file may contain a FeatureCollection, a single Feature or a bare geometry.
+func loadGeofence(fileName string) (orb.MultiPolygon, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("reading geofence: %w", err)
+	}
+
+	var header struct {
+		Type string `json:"type"`
+	}
+
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, fmt.Errorf("decoding geofence: %w", err)
+	}
+
+	var geometries []orb.Geometry
+
+	switch header.Type {
+	case "FeatureCollection":
+		fc, err := geojson.UnmarshalFeatureCollection(data)
+		if err != nil {
+			return nil, fmt.Errorf("decoding geofence: %w", err)
+		}
+
+		for _, feature := range fc.Features {
+			geometries = append(geometries, feature.Geometry)
+		}
+	case "Feature":
+		feature, err := geojson.UnmarshalFeature(data)
+		if err != nil {
+			return nil, fmt.Errorf("decoding geofence: %w", err)
+		}
+
+		geometries = append(geometries, feature.Geometry)
+	default:
+		geometry, err := geojson.UnmarshalGeometry(data)
+		if err != nil {
+			return nil, fmt.Errorf("decoding geofence: %w", err)
+		}
+
+		geometries = append(geometries, geometry.Geometry())
+	}
+
+	var polygons orb.MultiPolygon
+
+	for _, geometry := range geometries {
+		switch g := geometry.(type) {
+		case orb.Polygon:
+			polygons = append(polygons, g)
+		case orb.MultiPolygon:
+			polygons = append(polygons, g...)
+		}
+	}
+
+	if len(polygons) == 0 {
+		return nil, errors.New("geofence does not contain any polygon")
+	}
+
+	return polygons, nil
+}
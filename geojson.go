@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/florianloch/days-in-office/office"
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/geo"
+	"github.com/paulmach/orb/geojson"
+)
+
+// circleVertices is the number of vertices of the polygon approximating the tolerance around a location
+const circleVertices = 64
+
+// matchedPoint is a matching visit or point along with the date it is counted on
+type matchedPoint struct {
+	Date  string
+	Point office.TimelinePoint
+}
+
+// matchCollector collects the matching points for -format geojson.
+// Inputs are processed concurrently, so appends are serialized.
+type matchCollector struct {
+	mutex   sync.Mutex
+	matches []matchedPoint
+}
+
+// Collect is meant to be used as office.Options.Matched
+func (c *matchCollector) Collect(date string, point office.TimelinePoint) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.matches = append(c.matches, matchedPoint{Date: date, Point: point})
+}
+
+// writeGeoJSON writes a FeatureCollection with the matching points of the office days, the locations along with a
+// circle approximating their tolerance and the geofence if given
+func writeGeoJSON(w io.Writer, matches []matchedPoint, daysInTheOffice office.DayMap, locations []office.Location, geofence orb.MultiPolygon) error {
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].Point.Start.Before(matches[j].Point.Start)
+	})
+
+	collection := geojson.NewFeatureCollection()
+
+	for _, loc := range locations {
+		center := geojson.NewFeature(loc.Point)
+		center.Properties["location"] = loc.String()
+		center.Properties["tolerance"] = loc.Tolerance
+
+		area := geojson.NewFeature(circle(loc.Point, loc.Tolerance))
+		area.Properties["location"] = loc.String()
+
+		collection.Append(center)
+		collection.Append(area)
+	}
+
+	if geofence != nil {
+		fence := geojson.NewFeature(geofence)
+		fence.Properties["location"] = "geofence"
+
+		collection.Append(fence)
+	}
+
+	for _, match := range matches {
+		// Days removed afterwards, e.g. excluded dates, are left out
+		if _, ok := daysInTheOffice[match.Date]; !ok {
+			continue
+		}
+
+		feature := geojson.NewFeature(orb.Point{match.Point.Longitude, match.Point.Latitude})
+		feature.Properties["date"] = match.Date
+		feature.Properties["start"] = match.Point.Start
+		feature.Properties["end"] = match.Point.End
+
+		if nearest, distance, ok := office.RadiusMatcher(locations).Nearest(match.Point); ok {
+			feature.Properties["location"] = nearest.String()
+			feature.Properties["distance"] = math.Round(distance)
+		}
+
+		collection.Append(feature)
+	}
+
+	data, err := collection.MarshalJSON()
+	if err != nil {
+		return fmt.Errorf("encoding GeoJSON: %w", err)
+	}
+
+	if _, err := w.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("writing GeoJSON: %w", err)
+	}
+
+	return nil
+}
+
+// circle approximates the circle with the radius in meters around the center as a polygon
+func circle(center orb.Point, radius float64) orb.Polygon {
+	ring := make(orb.Ring, 0, circleVertices+1)
+
+	for i := 0; i < circleVertices; i++ {
+		ring = append(ring, geo.PointAtBearingAndDistance(center, float64(i)*360/circleVertices, radius))
+	}
+
+	// GeoJSON requires rings to be closed
+	ring = append(ring, ring[0])
+
+	return orb.Polygon{ring}
+}
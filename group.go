@@ -0,0 +1,199 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	mapset "github.com/deckarep/golang-set/v2"
+	"github.com/florianloch/days-in-office/office"
+)
+
+// groupKeys maps the values of -group-by to a function deriving the period of a date
+var groupKeys = map[string]func(time.Time) string{
+	"month": func(t time.Time) string {
+		return t.Format("2006-01")
+	},
+	"quarter": func(t time.Time) string {
+		return fmt.Sprintf("%d-Q%d", t.Year(), (int(t.Month())-1)/3+1)
+	},
+	"week": func(t time.Time) string {
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	},
+}
+
+// group holds the number of days in the office within a period, e.g. a month
+type group struct {
+	Key         string
+	Days        int
+	WorkingDays int
+}
+
+// groupDays aggregates the days by the period returned by key, sorted ascending by period
+func groupDays(daysInTheOffice office.DayMap, key func(time.Time) string) ([]group, error) {
+	groups := make(map[string]*group)
+
+	for date, isWorkingDay := range daysInTheOffice {
+		t, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			return nil, fmt.Errorf("parsing date %s: %w", date, err)
+		}
+
+		k := key(t)
+
+		g, ok := groups[k]
+		if !ok {
+			g = &group{Key: k}
+			groups[k] = g
+		}
+
+		g.Days++
+
+		if isWorkingDay {
+			g.WorkingDays++
+		}
+	}
+
+	result := make([]group, 0, len(groups))
+
+	for _, g := range groups {
+		result = append(result, *g)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Key < result[j].Key
+	})
+
+	return result, nil
+}
+
+func printGroups(w io.Writer, groups []group) {
+	for _, g := range groups {
+		fmt.Fprintf(w, "%s: %d day(s), %d working day(s)\n", g.Key, g.Days, g.WorkingDays)
+	}
+}
+
+// periodResult holds the number of working days in the office within a period, e.g. an ISO week
+type periodResult struct {
+	Period      string
+	WorkingDays int
+	MetTarget   bool
+	// Partial is set if the period is not fully inside the range, e.g. the month of a start date other than the 1st
+	Partial bool
+}
+
+// compliance lists every period returned by key touched by the range [start, end], including periods without any day
+// in the office. Periods only partially inside the range are marked, only their days within the range are counted.
+func compliance(daysInTheOffice office.DayMap, start, end time.Time, target int, key func(time.Time) string) []periodResult {
+	var result []periodResult
+
+	index := make(map[string]int)
+
+	for _, day := range office.Days(start, end) {
+		k := key(day)
+
+		i, ok := index[k]
+		if !ok {
+			i = len(result)
+			index[k] = i
+			result = append(result, periodResult{Period: k})
+		}
+
+		if daysInTheOffice[day.Format("2006-01-02")] {
+			result[i].WorkingDays++
+		}
+	}
+
+	for i := range result {
+		result[i].MetTarget = result[i].WorkingDays >= target
+	}
+
+	// Only the first and the last period can extend beyond the range
+	if len(result) > 0 {
+		if key(start.AddDate(0, 0, -1)) == result[0].Period {
+			result[0].Partial = true
+		}
+
+		if key(end.AddDate(0, 0, 1)) == result[len(result)-1].Period {
+			result[len(result)-1].Partial = true
+		}
+	}
+
+	return result
+}
+
+// printWeeklyCompliance lists whether the target was met in each week. Weeks only partially within the range cannot be
+// judged against the target, so they are listed as partial and left out of the summary.
+func printWeeklyCompliance(w io.Writer, weeks []periodResult, target int) {
+	met, total := 0, 0
+
+	for _, week := range weeks {
+		status := "missed"
+
+		switch {
+		case week.Partial:
+			status = "partial"
+		case week.MetTarget:
+			status = "met"
+			met++
+			total++
+		default:
+			total++
+		}
+
+		fmt.Fprintf(w, "%s: %d of %d working day(s) in the office, %s\n", week.Period, week.WorkingDays, target, status)
+	}
+
+	fmt.Fprintf(w, "Target met in %d of %d week(s)\n", met, total)
+}
+
+// anchorResult holds how many occurrences of a mandated weekday have been spent in the office
+type anchorResult struct {
+	Weekday  time.Weekday
+	InOffice int
+	Total    int
+}
+
+// anchorCompliance counts for each anchor weekday on how many of its occurrences among the working days the office was
+// visited, sorted by weekday starting with Monday
+func anchorCompliance(daysInTheOffice office.DayMap, workingDays []time.Time, anchors mapset.Set[time.Weekday]) []anchorResult {
+	var result []anchorResult
+
+	for i := 1; i <= 7; i++ {
+		weekday := time.Weekday(i % 7)
+		if !anchors.Contains(weekday) {
+			continue
+		}
+
+		r := anchorResult{Weekday: weekday}
+
+		for _, day := range workingDays {
+			if day.Weekday() != weekday {
+				continue
+			}
+
+			r.Total++
+
+			if _, ok := daysInTheOffice[day.Format("2006-01-02")]; ok {
+				r.InOffice++
+			}
+		}
+
+		result = append(result, r)
+	}
+
+	return result
+}
+
+func printAnchorCompliance(w io.Writer, anchors []anchorResult) {
+	for _, anchor := range anchors {
+		percentage := 0.0
+		if anchor.Total > 0 {
+			percentage = float64(anchor.InOffice) / float64(anchor.Total) * 100
+		}
+
+		fmt.Fprintf(w, "%s: in the office on %d of %d (%.0f%%)\n", anchor.Weekday.String()[:3], anchor.InOffice, anchor.Total, percentage)
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/florianloch/days-in-office/office"
+)
+
+func TestCompliancePartialMonths(t *testing.T) {
+	days := office.DayMap{"2024-03-04": true, "2024-03-05": true, "2024-04-01": true}
+
+	tests := []struct {
+		name  string
+		start time.Time
+		end   time.Time
+		want  []periodResult
+	}{
+		{
+			name:  "whole months",
+			start: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			end:   time.Date(2024, 4, 30, 23, 59, 59, 0, time.UTC),
+			want: []periodResult{
+				{Period: "2024-03", WorkingDays: 2, MetTarget: true},
+				{Period: "2024-04", WorkingDays: 1},
+			},
+		},
+		{
+			name:  "range starting and ending within a month",
+			start: time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC),
+			end:   time.Date(2024, 4, 2, 23, 59, 59, 0, time.UTC),
+			want: []periodResult{
+				{Period: "2024-03", WorkingDays: 2, MetTarget: true, Partial: true},
+				{Period: "2024-04", WorkingDays: 1, Partial: true},
+			},
+		},
+		{
+			name:  "range within a single month",
+			start: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			end:   time.Date(2024, 3, 15, 23, 59, 59, 0, time.UTC),
+			want: []periodResult{
+				{Period: "2024-03", WorkingDays: 2, MetTarget: true, Partial: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := compliance(days, tt.start, tt.end, 2, groupKeys["month"])
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("got %+v, want %+v", got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestWeeklyCompliancePartialWeeks(t *testing.T) {
+	days := office.DayMap{"2024-03-06": true, "2024-03-11": true, "2024-03-12": true, "2024-03-13": true}
+
+	// Wednesday of week 10 to Tuesday of week 12
+	start := time.Date(2024, 3, 6, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 3, 19, 23, 59, 59, 0, time.UTC)
+
+	weeks := compliance(days, start, end, 3, groupKeys["week"])
+
+	want := []periodResult{
+		{Period: "2024-W10", WorkingDays: 1, Partial: true},
+		{Period: "2024-W11", WorkingDays: 3, MetTarget: true},
+		{Period: "2024-W12", WorkingDays: 0, Partial: true},
+	}
+
+	if len(weeks) != len(want) {
+		t.Fatalf("got %+v, want %+v", weeks, want)
+	}
+
+	for i := range want {
+		if weeks[i] != want[i] {
+			t.Errorf("got %+v, want %+v", weeks[i], want[i])
+		}
+	}
+
+	var buf bytes.Buffer
+
+	printWeeklyCompliance(&buf, weeks, 3)
+
+	wantOutput := `2024-W10: 1 of 3 working day(s) in the office, partial
+2024-W11: 3 of 3 working day(s) in the office, met
+2024-W12: 0 of 3 working day(s) in the office, partial
+Target met in 1 of 1 week(s)
+`
+	if buf.String() != wantOutput {
+		t.Errorf("got output\n%s\nwant\n%s", buf.String(), wantOutput)
+	}
+}
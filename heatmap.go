@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/florianloch/days-in-office/office"
+)
+
+var (
+	heatmapOfficeStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("2"))
+	heatmapWorkingDayStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	heatmapWeekendStyle    = lipgloss.NewStyle().Faint(true)
+)
+
+// The glyphs differ per state so the heatmap stays readable without colors, e.g. if stdout is not a terminal
+const (
+	heatmapOfficeGlyph     = "■"
+	heatmapWorkingDayGlyph = "□"
+	heatmapWeekendGlyph    = "·"
+)
+
+// writeHeatmap renders a grid per month with one row per weekday and one column per week,
+// similar to the contribution graph of GitHub. Days outside of [start, end] are left empty.
+func writeHeatmap(w io.Writer, daysInTheOffice office.DayMap, cal office.Calendar, start, end time.Time) {
+	startOfFirstDay := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+	first := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, start.Location())
+
+	for month := first; !month.After(end); month = month.AddDate(0, 1, 0) {
+		fmt.Fprintln(w, month.Format("January 2006"))
+
+		// Weeks start on Monday, offset is the row of the first day of the month
+		offset := (int(month.Weekday()) + 6) % 7
+		daysInMonth := month.AddDate(0, 1, -1).Day()
+		weeks := (offset + daysInMonth + 6) / 7
+
+		rows := make([][]string, 7)
+		for row := range rows {
+			rows[row] = make([]string, weeks)
+
+			for week := range rows[row] {
+				rows[row][week] = " "
+			}
+		}
+
+		for day := month; day.Month() == month.Month(); day = day.AddDate(0, 0, 1) {
+			if day.Before(startOfFirstDay) || day.After(end) {
+				continue
+			}
+
+			cell := offset + day.Day() - 1
+			rows[cell%7][cell/7] = heatmapCell(daysInTheOffice, cal, day)
+		}
+
+		for row, cells := range rows {
+			weekday := time.Weekday((row + 1) % 7)
+			fmt.Fprintf(w, "%s %s\n", weekday.String()[:3], strings.Join(cells, " "))
+		}
+
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintf(w, "%s in the office  %s working day  %s non-working day\n",
+		heatmapOfficeStyle.Render(heatmapOfficeGlyph),
+		heatmapWorkingDayStyle.Render(heatmapWorkingDayGlyph),
+		heatmapWeekendStyle.Render(heatmapWeekendGlyph),
+	)
+}
+
+// heatmapCell returns the glyph of the day, colored if the output supports it
+func heatmapCell(daysInTheOffice office.DayMap, cal office.Calendar, day time.Time) string {
+	if _, ok := daysInTheOffice[day.Format("2006-01-02")]; ok {
+		return heatmapOfficeStyle.Render(heatmapOfficeGlyph)
+	}
+
+	if cal.IsWorkingDay(day) {
+		return heatmapWorkingDayStyle.Render(heatmapWorkingDayGlyph)
+	}
+
+	return heatmapWeekendStyle.Render(heatmapWeekendGlyph)
+}
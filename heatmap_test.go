@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	mapset "github.com/deckarep/golang-set/v2"
+	"github.com/florianloch/days-in-office/office"
+)
+
+// Without a terminal no colors are used, so the states have to be told apart by their glyphs
+func TestWriteHeatmapGlyphs(t *testing.T) {
+	cal := office.Calendar{
+		Weekend:  mapset.NewSet(time.Saturday, time.Sunday),
+		Holidays: mapset.NewSet("2024-03-06"),
+	}
+	days := office.DayMap{"2024-03-04": true}
+
+	var buf bytes.Buffer
+
+	writeHeatmap(&buf, days, cal, time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 10, 23, 59, 59, 0, time.UTC))
+
+	lines := strings.Split(buf.String(), "\n")
+
+	want := map[string]string{
+		"Mon": heatmapOfficeGlyph,
+		"Tue": heatmapWorkingDayGlyph,
+		"Wed": heatmapWeekendGlyph,
+		"Sat": heatmapWeekendGlyph,
+	}
+
+	for _, line := range lines {
+		if len(line) < 3 {
+			continue
+		}
+
+		glyph, ok := want[line[:3]]
+		if !ok {
+			continue
+		}
+
+		// March 2024 starts on a Friday, the days from the 4th on are in the second column
+		cells := strings.Fields(line[3:])
+		if len(cells) != 1 || cells[0] != glyph {
+			t.Errorf("%s: got %q, want %q", line[:3], line[3:], glyph)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+)
+
+// loadIgnorePatterns reads a file with one glob pattern per line, empty lines and lines starting with # are ignored.
+// Patterns containing a slash are matched against the path relative to the input directory, others against the name
+// of each file and directory, a trailing slash is ignored.
+func loadIgnorePatterns(fileName string) ([]string, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("opening file: %w", err)
+	}
+	defer file.Close()
+
+	var patterns []string
+
+	scanner := bufio.NewScanner(file)
+	lineNumber := 0
+
+	for scanner.Scan() {
+		lineNumber++
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		pattern := strings.Trim(line, "/")
+
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("line %d: invalid pattern %q: %w", lineNumber, line, err)
+		}
+
+		patterns = append(patterns, pattern)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading file: %w", err)
+	}
+
+	return patterns, nil
+}
+
+// isIgnored reports whether the file or directory at the slash-separated path relative to the input directory matches
+// any of the ignore patterns. Files within ignored directories are not checked, as the directories are skipped.
+func isIgnored(rel string, patterns []string) bool {
+	name := path.Base(rel)
+
+	for _, pattern := range patterns {
+		target := name
+		if strings.Contains(pattern, "/") {
+			target = rel
+		}
+
+		// The patterns have been validated while loading, so errors can be ignored
+		if matched, _ := path.Match(pattern, target); matched {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/florianloch/days-in-office/office"
+	"github.com/paulmach/orb"
+)
+
+// locationList implements flag.Value so that -location can be passed multiple times
+type locationList []office.Location
+
+func (l *locationList) String() string {
+	parts := make([]string, 0, len(*l))
+
+	for _, loc := range *l {
+		parts = append(parts, fmt.Sprintf("%g,%g,%g", loc.Point.Lat(), loc.Point.Lon(), loc.Tolerance))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func (l *locationList) Set(value string) error {
+	// "52.37,4.89,1000"
+	parts := strings.Split(value, ",")
+	if len(parts) != 3 {
+		return fmt.Errorf("expected \"latitude,longitude,tolerance\", got %q", value)
+	}
+
+	values := make([]float64, 0, 2)
+
+	for _, part := range parts[:2] {
+		v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return fmt.Errorf("parsing %q: %w", value, err)
+		}
+
+		values = append(values, v)
+	}
+
+	tolerance, err := parseDistance(parts[2])
+	if err != nil {
+		return fmt.Errorf("parsing %q: %w", value, err)
+	}
+
+	*l = append(*l, office.Location{
+		Point:     orb.Point{values[1], values[0]},
+		Tolerance: tolerance,
+	})
+
+	return nil
+}
+
+// distanceUnit is a unit distances can be given in
+type distanceUnit struct {
+	suffix string
+	meters float64
+	// decimals is the number of decimal places distances are printed with
+	decimals int
+}
+
+// format formats the distance given in meters in the unit, e.g. "212m" or "0.21km"
+func (u distanceUnit) format(meters float64) string {
+	return strconv.FormatFloat(meters/u.meters, 'f', u.decimals, 64) + u.suffix
+}
+
+// distanceUnits lists the supported units.
+// Longer suffixes are listed first, so "km" is not mistaken for "m".
+var distanceUnits = []distanceUnit{
+	{"km", 1000, 2},
+	{"mi", 1609.344, 2},
+	{"ft", 0.3048, 0},
+	{"m", 1, 0},
+}
+
+// defaultDistanceUnit is the unit of distances given without one
+var defaultDistanceUnit = distanceUnits[len(distanceUnits)-1]
+
+// unitOf returns the unit a distance like "0.5km" is given in, meters if it has none
+func unitOf(value string) distanceUnit {
+	trimmed := strings.TrimSpace(value)
+
+	for _, unit := range distanceUnits {
+		if strings.HasSuffix(trimmed, unit.suffix) {
+			return unit
+		}
+	}
+
+	return defaultDistanceUnit
+}
+
+// parseDistance parses a distance like "500m", "0.5km", "1mi" or "300ft" and returns it in meters.
+// Numbers without a unit are meters.
+func parseDistance(value string) (float64, error) {
+	trimmed := strings.TrimSpace(value)
+
+	for _, unit := range distanceUnits {
+		if number, ok := strings.CutSuffix(trimmed, unit.suffix); ok {
+			distance, err := strconv.ParseFloat(strings.TrimSpace(number), 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid distance %q: %w", value, err)
+			}
+
+			return distance * unit.meters, nil
+		}
+	}
+
+	distance, err := strconv.ParseFloat(trimmed, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid distance %q: %w", value, err)
+	}
+
+	return distance, nil
+}
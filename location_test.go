@@ -0,0 +1,93 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseDistance(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    float64
+		wantErr bool
+	}{
+		{value: "500", want: 500},
+		{value: "12.5", want: 12.5},
+		{value: "500m", want: 500},
+		{value: "0.5km", want: 500},
+		{value: "1mi", want: 1609.344},
+		{value: "300ft", want: 91.44},
+		{value: "500 m", want: 500},
+		{value: "1.5  km", want: 1500},
+		{value: " 2 mi ", want: 3218.688},
+		{value: "", wantErr: true},
+		{value: "abc", wantErr: true},
+		{value: "km", wantErr: true},
+		{value: "1..5km", wantErr: true},
+		{value: "5 meters", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseDistance(tt.value)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %g", got)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("got %gm, want %gm", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnitOf(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "100", want: "m"},
+		{value: "100m", want: "m"},
+		{value: "0.5km", want: "km"},
+		{value: "1 mi", want: "mi"},
+		{value: "300ft ", want: "ft"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := unitOf(tt.value).suffix; got != tt.want {
+				t.Errorf("got unit %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDistanceUnitFormat(t *testing.T) {
+	tests := []struct {
+		unit   string
+		meters float64
+		want   string
+	}{
+		{unit: "212m", meters: 212.4, want: "212m"},
+		{unit: "1km", meters: 212.4, want: "0.21km"},
+		{unit: "1mi", meters: 1609.344, want: "1.00mi"},
+		{unit: "1ft", meters: 3.048, want: "10ft"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := unitOf(tt.unit).format(tt.meters); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,304 +1,1079 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path"
+	"path/filepath"
+	"runtime"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/charmbracelet/log"
+	mapset "github.com/deckarep/golang-set/v2"
+	"github.com/florianloch/days-in-office/office"
 	"github.com/paulmach/orb"
-	"github.com/paulmach/orb/geo"
 )
 
 func main() {
-	inputDirFlag := flag.String("input-dir", "", "Directory containing the input JSON files")
-	startDateFlag := flag.String("start-date", "", "Start of time range to consider, example: 2020-01-01T00:00:00")
-	endDateFlag := flag.String("end-date", "", "End of time range to consider")
+	if err := run(); err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			err = fmt.Errorf("processing has not finished within -timeout: %w", err)
+		}
+
+		log.Error(err)
+
+		var usageErr usageError
+		if errors.As(err, &usageErr) {
+			os.Exit(2)
+		}
+
+		os.Exit(1)
+	}
+}
+
+// logFormatters maps the values of -log-format to the formatters of the logger
+var logFormatters = map[string]log.Formatter{
+	"text": log.TextFormatter,
+	"json": log.JSONFormatter,
+}
+
+// stringList implements flag.Value so that a flag can be passed multiple times
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, " ")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// usageError indicates an invalid invocation, e.g. a missing or malformed flag, resulting in exit code 2
+type usageError struct {
+	err error
+}
+
+func (e usageError) Error() string {
+	return e.err.Error()
+}
+
+func (e usageError) Unwrap() error {
+	return e.err
+}
+
+func usageErrorf(format string, args ...any) error {
+	return usageError{err: fmt.Errorf(format, args...)}
+}
+
+func run() error {
+	command, args := parseCommand(os.Args[1:])
+
+	var inputDirsFlag stringList
+	flag.Var(&inputDirsFlag, "input-dir", "Directory containing the input JSON files, can be passed multiple times to combine several exports, \"-\" reads a single JSON document from stdin")
+	patternFlag := flag.String("pattern", "*.json,*.json.gz,*.gpx,*.gpx.gz", "Comma-separated list of glob patterns, only files with a matching name are read from -input-dir")
+	ignoreFlag := flag.String("ignore", "", "File listing glob patterns of files and directories to skip in -input-dir, one per line")
+	followSymlinksFlag := flag.Bool("follow-symlinks", false, "Read symlinked files and directories within -input-dir, which are skipped by default")
+	perPersonFlag := flag.Bool("per-person", false, "Report the days of each person separately, -input-dir has to contain one subdirectory per person")
+	inputZipFlag := flag.String("input-zip", "", "Google Takeout zip archive to read the input JSON files from, alternative to -input-dir")
+	startDateFlag := flag.String("start-date", "", "Start of time range to consider, example: 2020-01-01, 2020-01-01T00:00:00Z or 30d ago (default all visits)")
+	endDateFlag := flag.String("end-date", "", "End of time range to consider, a date without time or a relative date like today includes the whole day (default now)")
+	inclusiveEndFlag := flag.Bool("inclusive-end", false, "Include the whole day of -end-date, even if it is given with a time")
 	latitudeFlag := flag.String("latitude", "", "Latitude of the location")
 	longitudeFlag := flag.String("longitude", "", "Longitude of the location")
-	toleranceFlag := flag.String("tolerance", "1000", "Radius around location in meters, contained places are considered as the location ")
+	coordsFlag := flag.String("coords", "", "Latitude and longitude of the location as copied from Google Maps, example: \"52.370216, 4.895168\"")
+	toleranceFlag := flag.String("tolerance", "1000", "Radius around location, contained places are considered as the location, units: m (default), km, mi, ft")
+	var locationsFlag locationList
+	flag.Var(&locationsFlag, "location", "Additional location given as \"latitude,longitude,tolerance\", can be passed multiple times")
+	sweepFlag := flag.String("sweep", "", "Comma-separated list of tolerances, prints the number of days for each of them instead of the regular output, example: 250,500,1km")
+	var excludedLocationsFlag locationList
+	flag.Var(&excludedLocationsFlag, "exclude-location", "Location given as \"latitude,longitude,tolerance\" whose visits are never counted, e.g. your home next to the office, can be passed multiple times")
+	minDurationFlag := flag.String("min-duration", "0s", "Minimum duration of a visit to be considered, example: 30m")
+	coreRadiusFlag := flag.String("core-radius", "", "Radius around the location smaller than -tolerance within which visits count regardless of -min-duration, same units as -tolerance")
+	minConfidenceFlag := flag.Int("min-confidence", 0, "Minimum visit confidence (0-100) of a place visit to be considered, only available in the legacy format")
+	minProbabilityFlag := flag.Float64("min-probability", 0, "Minimum probability (0-1) of the visited place to be considered, only available in the newer format")
+	minPointsPerDayFlag := flag.Int("min-points-per-day", 1, "Minimum number of matching visits or points on a day for it to be counted")
+	geofenceFlag := flag.String("geofence", "", "GeoJSON file with polygons of the location, takes precedence over the radius around the location")
+	configFlag := flag.String("config", "", "JSON file defining named locations")
 	verboseFlag := flag.Bool("verbose", false, "Verbose output")
+	quietFlag := flag.Bool("quiet", false, "Only log warnings and errors")
+	logFormatFlag := flag.String("log-format", "text", "Format of log output, one of: text, json")
+	onlyFlag := flag.String("only", "", "Only count and print days of one kind, one of: working, weekend (default both)")
 	printDatesFlag := flag.Bool("print-dates", false, "Print dates")
+	formatFlag := flag.String("format", "text", "Output format, one of: text, json, csv, ics, heatmap, markdown, geojson")
+	groupByFlag := flag.String("group-by", "", "Print the number of days per period, one of: month, quarter, week")
+	anchorDaysFlag := flag.String("anchor-days", "", "Comma-separated list of mandated weekdays, prints on which share of them you were in the office, example: Tue,Thu")
+	requirePerMonthFlag := flag.Int("require-per-month", 0, "Fail if less than this many working days were spent in the office in any month of the time range")
+	targetPerWeekFlag := flag.Int("target-per-week", 0, "Print for each week of the time range whether at least this many working days were spent in the office")
+	weekendFlag := flag.String("weekend", "Sat,Sun", "Comma-separated list of weekend days, which are not counted as working days")
+	holidaysFlag := flag.String("holidays", "", "File listing public holidays, one date formatted as 2006-01-02 per line, which are not counted as working days")
+	excludeDatesFlag := flag.String("exclude-dates", "", "Comma-separated list of dates formatted as 2006-01-02 which are never counted, e.g. known false positives")
+	excludeDatesFileFlag := flag.String("exclude-dates-file", "", "File listing dates which are never counted, one date formatted as 2006-01-02 per line")
+	timezoneFlag := flag.String("timezone", "", "Timezone used to determine the date of visits, example: Europe/Amsterdam (default local timezone)")
+	countSpannedDaysFlag := flag.Bool("count-spanned-days", false, "Count every date a visit spans, e.g. both days of a visit from 22:00 to 01:00, instead of only the date it started on")
+	useVisitTimezoneFlag := flag.Bool("use-visit-timezone", false, "Determine the date of each visit using the UTC offset of its timestamp instead of -timezone, e.g. when you moved timezones")
+	allowNullIslandFlag := flag.Bool("allow-null-island", false, "Consider visits at exactly (0,0), which are skipped by default as they usually lack coordinates")
+	redactCoordsFlag := flag.Bool("redact-coords", false, "Round or leave out coordinates in log output, so verbose logs can be shared")
+	preferLocationCoordsFlag := flag.Bool("prefer-location-coords", false, "Use the coordinates of the place instead of the center of the visit in the legacy format")
+	includeActivitiesFlag := flag.Bool("include-activities", false, "Also consider the start and end points of movements between places")
+	concurrencyFlag := flag.Int("concurrency", runtime.NumCPU(), "Number of files processed in parallel")
+	dedupeFlag := flag.Bool("dedupe", false, "Skip visits already seen in another file, e.g. at the boundaries of monthly files")
+	dateFormatFlag := flag.String("date-format", "iso", "Format of printed dates, either a Go layout or one of: iso, eu, us")
+	statsFlag := flag.String("stats", "", "Comma-separated list of statistics to print, available: weekday, streak, hours, locations, coverage")
+	outputFlag := flag.String("output", "", "File to write the output to instead of stdout, it is truncated if it exists")
+	progressFlag := flag.Bool("progress", false, "Show the number of processed files on stderr (default if stderr is a terminal)")
+	dumpNormalizedFlag := flag.Bool("dump-normalized", false, "Write all parsed points as a JSON array instead of the regular output, regardless of the input format")
+	timeoutFlag := flag.String("timeout", "0s", "Maximum duration of processing the input, example: 5m (default no limit)")
+	cacheDirFlag := flag.String("cache-dir", "", "Directory to cache parsed files in, unchanged files are not parsed again on later runs")
+	strictFlag := flag.Bool("strict", false, "Fail if any file cannot be read or parsed instead of skipping it")
+	listFilesFlag := flag.Bool("list-files", false, "Print the files that would be read and exit without processing them")
+	versionFlag := flag.Bool("version", false, "Print the version and exit")
+	csvHeaderFlag := flag.Bool("csv-header", true, "Write a header row when using -format csv")
+
+	flag.Usage = usage
+	// flag.CommandLine exits on invalid flags by itself
+	_ = flag.CommandLine.Parse(args)
+
+	if *versionFlag {
+		printVersion(os.Stdout)
+		return nil
+	}
 
-	flag.Parse()
+	if *verboseFlag && *quietFlag {
+		return usageErrorf("-verbose and -quiet are mutually exclusive")
+	}
 
 	logLevel := log.InfoLevel
 	if *verboseFlag {
 		logLevel = log.DebugLevel
+	} else if *quietFlag {
+		logLevel = log.WarnLevel
+	}
+
+	logFormatter, ok := logFormatters[*logFormatFlag]
+	if !ok {
+		return usageErrorf("unknown log format %q", *logFormatFlag)
 	}
 
 	log.SetDefault(log.NewWithOptions(os.Stderr, log.Options{
-		Level: logLevel,
+		Level:     logLevel,
+		Formatter: logFormatter,
 	}))
 
-	// TODO: Validate input
+	// All flags are validated before any input is read, so mistakes do not result in confusing empty results
+
+	if len(inputDirsFlag) > 0 && *inputZipFlag != "" {
+		return usageErrorf("-input-dir and -input-zip are mutually exclusive")
+	}
+
+	if len(inputDirsFlag) == 0 && *inputZipFlag == "" {
+		return usageErrorf("either -input-dir or -input-zip is required")
+	}
+
+	readStdin := len(inputDirsFlag) == 1 && inputDirsFlag[0] == "-"
 
-	latitude, err := strconv.ParseFloat(*latitudeFlag, 64)
+	if len(inputDirsFlag) > 1 {
+		for _, inputDir := range inputDirsFlag {
+			if inputDir == "-" {
+				return usageErrorf("reading from stdin cannot be combined with other input directories")
+			}
+		}
+	}
+
+	patterns := strings.Split(*patternFlag, ",")
+
+	for i, pattern := range patterns {
+		patterns[i] = strings.TrimSpace(pattern)
+
+		if _, err := filepath.Match(patterns[i], ""); err != nil {
+			return usageErrorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+
+	var ignorePatterns []string
+
+	if *ignoreFlag != "" {
+		var err error
+
+		ignorePatterns, err = loadIgnorePatterns(*ignoreFlag)
+		if err != nil {
+			return usageErrorf("could not load ignore file: %w", err)
+		}
+	}
+
+	if *listFilesFlag {
+		var fileNames []string
+		var err error
+
+		if *inputZipFlag != "" {
+			fileNames, err = office.ListZipEntries(*inputZipFlag)
+		} else {
+			fileNames, err = listInputFiles(inputDirsFlag, patterns, ignorePatterns, *followSymlinksFlag)
+		}
+
+		if err != nil {
+			return fmt.Errorf("could not list files: %w", err)
+		}
+
+		for _, fileName := range fileNames {
+			fmt.Println(fileName)
+		}
+
+		return nil
+	}
+
+	switch *formatFlag {
+	case "text", "json", "csv", "ics", "heatmap", "markdown", "geojson":
+	default:
+		return usageErrorf("unknown output format %q", *formatFlag)
+	}
+
+	switch command {
+	case "dump":
+		switch *formatFlag {
+		case "text", "json":
+			*dumpNormalizedFlag = true
+			*formatFlag = "text"
+		case "geojson":
+		default:
+			return usageErrorf("dump only supports the formats json and geojson, got %q", *formatFlag)
+		}
+	case "validate":
+		if *perPersonFlag || *sweepFlag != "" || *dumpNormalizedFlag || *formatFlag != "text" {
+			return usageErrorf("validate only reports issues and cannot be combined with -per-person, -sweep, -dump-normalized or -format")
+		}
+	}
+
+	if *dumpNormalizedFlag && (*perPersonFlag || *formatFlag != "text") {
+		return usageErrorf("-dump-normalized replaces the regular output and cannot be combined with -per-person or -format")
+	}
+
+	if *perPersonFlag {
+		if len(inputDirsFlag) != 1 || readStdin {
+			return usageErrorf("-per-person requires a single -input-dir with one subdirectory per person")
+		}
+
+		if *formatFlag != "text" && *formatFlag != "csv" {
+			return usageErrorf("-per-person only supports the formats text and csv, got %q", *formatFlag)
+		}
+	}
+
+	dateFormat, err := parseDateFormat(*dateFormatFlag)
 	if err != nil {
-		log.Error("Could not parse latitude", "err", err)
+		return usageErrorf("could not parse date format: %w", err)
 	}
 
-	longitude, err := strconv.ParseFloat(*longitudeFlag, 64)
+	reports, err := parseStats(*statsFlag)
 	if err != nil {
-		log.Error("Could not parse longitude", "err", err)
+		return usageErrorf("could not parse statistics: %w", err)
+	}
 
+	groupKey, ok := groupKeys[*groupByFlag]
+	if *groupByFlag != "" && !ok {
+		return usageErrorf("unknown grouping %q", *groupByFlag)
 	}
 
-	tolerance, err := strconv.ParseFloat(*toleranceFlag, 64)
-	if err != nil {
-		log.Error("Could not parse tolerance", "err", err)
+	if *targetPerWeekFlag < 0 || *targetPerWeekFlag > 7 {
+		return usageErrorf("target per week has to be within [0, 7], got %d", *targetPerWeekFlag)
+	}
+
+	switch *onlyFlag {
+	case "", "working", "weekend":
+	default:
+		return usageErrorf("unknown kind of days %q, expected working or weekend", *onlyFlag)
 	}
 
-	startDate, err := time.ParseInLocation(time.RFC3339, *startDateFlag, time.Local)
+	if *requirePerMonthFlag < 0 || *requirePerMonthFlag > 31 {
+		return usageErrorf("required days per month have to be within [0, 31], got %d", *requirePerMonthFlag)
+	}
+
+	weekend, err := parseWeekdays(*weekendFlag)
 	if err != nil {
-		log.Error("Could not parse start date", "err", err)
+		return usageErrorf("could not parse weekend: %w", err)
+	}
+
+	cal := office.Calendar{
+		Weekend: weekend,
 	}
 
-	endDate, err := time.ParseInLocation(time.RFC3339, *endDateFlag, time.Local)
+	anchors, err := parseWeekdays(*anchorDaysFlag)
 	if err != nil {
-		log.Error("Could not parse end date", "err", err)
+		return usageErrorf("could not parse anchor days: %w", err)
 	}
 
-	fileNames, err := listFilesRecursively(*inputDirFlag)
+	if *holidaysFlag != "" {
+		cal.Holidays, err = loadDates(*holidaysFlag)
+		if err != nil {
+			return usageErrorf("could not load holidays: %w", err)
+		}
+	}
+
+	excludedDates, err := parseDateList(*excludeDatesFlag)
 	if err != nil {
-		log.Error("Could not list files", "err", err)
+		return usageErrorf("could not parse excluded dates: %w", err)
 	}
 
-	officeLocation := orb.Point{latitude, longitude}
-	daysInTheOffice := make(dayMap)
+	if *excludeDatesFileFlag != "" {
+		excludedDatesFromFile, err := loadDates(*excludeDatesFileFlag)
+		if err != nil {
+			return usageErrorf("could not load excluded dates: %w", err)
+		}
 
-	for _, fileName := range fileNames {
-		processFile(fileName, startDate, endDate, officeLocation, tolerance, daysInTheOffice)
+		excludedDates = excludedDates.Union(excludedDatesFromFile)
 	}
 
-	log.Infof("You have been in the office on %d day(s) of which %d have been working days.", len(daysInTheOffice), daysInTheOffice.CountWorkingDays())
+	if *concurrencyFlag < 1 {
+		return usageErrorf("concurrency has to be at least 1, got %d", *concurrencyFlag)
+	}
 
-	if *printDatesFlag {
-		list := daysInTheOffice.ToSlice()
+	tolerance, err := parseDistance(*toleranceFlag)
+	if err != nil {
+		return usageErrorf("could not parse tolerance: %w", err)
+	}
 
-		sort.Strings(list)
+	var locations []office.Location
 
-		for _, date := range list {
-			fmt.Print(date)
+	if *configFlag != "" {
+		configLocations, err := loadConfig(*configFlag, tolerance)
+		if err != nil {
+			return usageErrorf("could not load config: %w", err)
+		}
 
-			if !daysInTheOffice[date] {
-				fmt.Print(" (weekend)")
+		locations = append(locations, configLocations...)
+	}
+
+	// Locations given on the command line are added to the ones from the config file.
+	// The single location flags are kept for backward compatibility, they simply add another location.
+	if *coordsFlag != "" && (*latitudeFlag != "" || *longitudeFlag != "") {
+		return usageErrorf("-coords and -latitude/-longitude are mutually exclusive")
+	}
+
+	if *coordsFlag != "" || *latitudeFlag != "" || *longitudeFlag != "" {
+		var latitude, longitude float64
+
+		if *coordsFlag != "" {
+			latitude, longitude, err = office.ParsePoint(*coordsFlag)
+			if err != nil {
+				return usageErrorf("could not parse coordinates: %w", err)
+			}
+		} else {
+			latitude, err = strconv.ParseFloat(*latitudeFlag, 64)
+			if err != nil {
+				return usageErrorf("could not parse latitude: %w", err)
 			}
 
-			fmt.Print("\n")
+			longitude, err = strconv.ParseFloat(*longitudeFlag, 64)
+			if err != nil {
+				return usageErrorf("could not parse longitude: %w", err)
+			}
 		}
+
+		locations = append(locations, office.Location{
+			Point:     orb.Point{longitude, latitude},
+			Tolerance: tolerance,
+		})
 	}
-}
 
-// dayMap maps a stringified date to a boolean indicating whether it was a working day
-type dayMap map[string]bool
+	locations = append(locations, locationsFlag...)
 
-func (d dayMap) Add(t time.Time) {
-	date := t.Format("2006-01-02")
-	d[date] = t.Weekday() != time.Saturday && t.Weekday() != time.Sunday
-}
+	var geofence orb.MultiPolygon
 
-func (d dayMap) ToSlice() []string {
-	slice := make([]string, 0, len(d))
+	if *geofenceFlag != "" {
+		var err error
 
-	for key := range d {
-		slice = append(slice, key)
+		geofence, err = loadGeofence(*geofenceFlag)
+		if err != nil {
+			return usageErrorf("could not load geofence: %w", err)
+		}
 	}
 
-	return slice
-}
+	for _, loc := range locations {
+		if err := loc.Validate(); err != nil {
+			return usageErrorf("location %s is invalid: %w", loc, err)
+		}
+	}
+
+	for _, loc := range excludedLocationsFlag {
+		if err := loc.Validate(); err != nil {
+			return usageErrorf("excluded location %s is invalid: %w", loc, err)
+		}
+	}
+
+	// Dumping the parsed points and validating the input do not need a location, only the map of matches does
+	if len(locations) == 0 && geofence == nil && ((command == "count" && !*dumpNormalizedFlag) || *formatFlag == "geojson") {
+		return usageErrorf("no location given, use -latitude/-longitude, -location, -config or -geofence")
+	}
+
+	var sweepTolerances []float64
+
+	if *sweepFlag != "" {
+		if geofence != nil || readStdin || *perPersonFlag || *dumpNormalizedFlag || *formatFlag != "text" {
+			return usageErrorf("-sweep cannot be combined with -geofence, -per-person, -dump-normalized, -format or reading from stdin")
+		}
 
-func (d dayMap) CountWorkingDays() int {
-	count := 0
+		sweepTolerances, err = parseDistances(*sweepFlag)
+		if err != nil {
+			return usageErrorf("could not parse tolerances to sweep: %w", err)
+		}
+	}
 
-	for _, isWorkingDay := range d {
-		if isWorkingDay {
-			count++
+	timezone := time.Local
+
+	if *timezoneFlag != "" {
+		timezone, err = time.LoadLocation(*timezoneFlag)
+		if err != nil {
+			return usageErrorf("could not load timezone: %w", err)
 		}
 	}
 
-	return count
-}
+	// Both ends of the time range are optional, an omitted start date includes all visits up to the end date
+	// and an omitted end date includes all visits up to now.
+	var startDate time.Time
 
-func processFile(fileName string, startDate, endDate time.Time, officeLocation orb.Point, tolerance float64, daysInTheOffice dayMap) {
-	logger := log.With("file", fileName)
+	if *startDateFlag != "" {
+		startDate, err = parseDate(*startDateFlag, timezone, false)
+		if err != nil {
+			return usageErrorf("could not parse start date: %w", err)
+		}
+	}
 
-	file, err := os.OpenFile(fileName, os.O_RDONLY, 0)
+	endDate := time.Now().In(timezone)
+
+	if *endDateFlag != "" {
+		endDate, err = parseEndDate(*endDateFlag, timezone, *inclusiveEndFlag)
+		if err != nil {
+			return usageErrorf("could not parse end date: %w", err)
+		}
+	}
+
+	if !startDate.Before(endDate) {
+		return usageErrorf("start date %s has to be before end date %s", startDate.Format(time.RFC3339), endDate.Format(time.RFC3339))
+	}
+
+	timeout, err := time.ParseDuration(*timeoutFlag)
 	if err != nil {
-		logger.Error("Could not open file", "err", err)
+		return usageErrorf("could not parse timeout: %w", err)
 	}
 
-	places, err := ParseTimelineInput(file)
+	minDuration, err := time.ParseDuration(*minDurationFlag)
 	if err != nil {
-		logger.Error("Could not parse file", "err", err)
+		return usageErrorf("could not parse minimum duration: %w", err)
 	}
 
-	placesProcessed := 0
+	var coreRadius float64
 
-	for _, place := range places {
-		if place.End.Before(startDate) || place.Start.After(endDate) {
-			// We expect entries to be in sorted order, so we could break here.
-			// But as we do not know for sure we instead go the extra mile.
-			continue
+	if *coreRadiusFlag != "" {
+		if geofence != nil {
+			return usageErrorf("-core-radius cannot be combined with -geofence")
 		}
 
-		loc := orb.Point{place.Latitude, place.Longitude}
-
-		distance := geo.DistanceHaversine(officeLocation, loc)
+		coreRadius, err = parseDistance(*coreRadiusFlag)
+		if err != nil {
+			return usageErrorf("could not parse core radius: %w", err)
+		}
 
-		if distance <= tolerance {
-			daysInTheOffice.Add(place.Start)
+		for _, loc := range locations {
+			if coreRadius >= loc.Tolerance {
+				return usageErrorf("core radius %gm has to be smaller than the tolerance %gm of location %s", coreRadius, loc.Tolerance, loc)
+			}
 		}
+	}
 
-		placesProcessed++
+	if *minConfidenceFlag < 0 || *minConfidenceFlag > 100 {
+		return usageErrorf("minimum confidence has to be within [0, 100], got %d", *minConfidenceFlag)
 	}
 
-	logger.Debugf("Found %d visits to places in file of which %d have been (partially) within the given time range", len(places), placesProcessed)
-}
+	if *minProbabilityFlag < 0 || *minProbabilityFlag > 1 {
+		return usageErrorf("minimum probability has to be within [0, 1], got %g", *minProbabilityFlag)
+	}
 
-func listFilesRecursively(inputDir string) ([]string, error) {
-	var list []string
+	if *minPointsPerDayFlag < 1 {
+		return usageErrorf("minimum points per day has to be at least 1, got %d", *minPointsPerDayFlag)
+	}
 
-	var readDir func(string) error
-	readDir = func(inputDir string) error {
-		entries, err := os.ReadDir(inputDir)
+	opts := office.Options{
+		StartDate:        startDate,
+		EndDate:          endDate,
+		Locations:        locations,
+		Geofence:         geofence,
+		MinDuration:      minDuration,
+		CoreRadius:       coreRadius,
+		MinConfidence:    *minConfidenceFlag,
+		MinProbability:   *minProbabilityFlag,
+		Calendar:         cal,
+		IsWorkingDay:     cal.IsWorkingDay,
+		Timezone:         timezone,
+		UseVisitTimezone: *useVisitTimezoneFlag,
+		CountSpannedDays: *countSpannedDaysFlag,
+		Parse: office.ParseOptions{
+			IncludeActivities: *includeActivitiesFlag,
+			PreferLocation:    *preferLocationCoordsFlag,
+			RedactCoords:      *redactCoordsFlag,
+		},
+		Concurrency:       *concurrencyFlag,
+		MinPointsPerDay:   *minPointsPerDayFlag,
+		ExcludedDates:     excludedDates,
+		ExcludedLocations: excludedLocationsFlag,
+		AllowNullIsland:   *allowNullIslandFlag,
+		Strict:            *strictFlag,
+		CacheDir:          *cacheDirFlag,
+	}
+
+	if len(locations) == 0 && geofence == nil {
+		opts.Matcher = noMatcher{}
+	}
+
+	// Progress is shown in interactive sessions unless it would mix with machine-readable or suppressed logs
+	if *progressFlag || (isTerminal(os.Stderr) && !*quietFlag && *logFormatFlag == "text") {
+		opts.Progress = printProgress
+	}
+
+	if *dedupeFlag {
+		// Files are processed concurrently, so the set has to be thread-safe
+		opts.SeenVisits = mapset.NewSet[office.VisitKey]()
+	}
+
+	ctx := context.Background()
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	if *perPersonFlag {
+		people, err := countPerPerson(ctx, inputDirsFlag[0], patterns, ignorePatterns, *followSymlinksFlag, opts, *dedupeFlag, *onlyFlag)
 		if err != nil {
-			return fmt.Errorf("could not read directory %s: %w", inputDir, err)
+			return err
 		}
 
-		for _, entry := range entries {
-			fullPath := path.Join(inputDir, entry.Name())
+		out, closeOutput, err := createOutput(*outputFlag)
+		if err != nil {
+			return err
+		}
+		defer closeOutput()
 
-			if entry.IsDir() {
-				err := readDir(fullPath)
-				if err != nil {
-					return err
-				}
-			} else {
-				list = append(list, fullPath)
+		if *formatFlag == "csv" {
+			if err := writePersonsCSV(out, people, *csvHeaderFlag, dateFormat); err != nil {
+				return fmt.Errorf("could not write CSV: %w", err)
 			}
+		} else {
+			printPersons(out, people)
+		}
+
+		return closeOutput()
+	}
+
+	count := func(opts office.Options) (office.Result, error) {
+		if readStdin {
+			// There is no file name when reading from stdin, so "stdin" is used for the file field in logs
+			return office.CountDaysInReader(ctx, "stdin", os.Stdin, opts)
+		}
+
+		if *inputZipFlag != "" {
+			result, err := office.CountDaysInZip(ctx, *inputZipFlag, opts)
+			if err != nil {
+				return office.Result{}, fmt.Errorf("could not read zip archive: %w", err)
+			}
+
+			return result, nil
+		}
+
+		fileNames, err := listInputFiles(inputDirsFlag, patterns, ignorePatterns, *followSymlinksFlag)
+		if err != nil {
+			return office.Result{}, fmt.Errorf("could not list files: %w", err)
+		}
+
+		return office.CountDaysInOffice(ctx, fileNames, opts)
+	}
+
+	if command == "validate" {
+		// All issues are reported instead of stopping at the first one
+		opts.Strict = false
+
+		result, err := count(opts)
+		if err != nil {
+			return err
+		}
+
+		out, closeOutput, err := createOutput(*outputFlag)
+		if err != nil {
+			return err
+		}
+		defer closeOutput()
+
+		issues := printValidation(out, result.Stats)
+
+		if err := closeOutput(); err != nil {
+			return err
+		}
+
+		if issues > 0 {
+			return fmt.Errorf("%d file(s) have issues", issues)
 		}
 
 		return nil
 	}
 
-	if err := readDir(inputDir); err != nil {
-		return nil, err
+	if sweepTolerances != nil {
+		results, err := sweep(count, opts, sweepTolerances, *dedupeFlag)
+		if err != nil {
+			return err
+		}
+
+		out, closeOutput, err := createOutput(*outputFlag)
+		if err != nil {
+			return err
+		}
+		defer closeOutput()
+
+		printSweep(out, results)
+
+		return closeOutput()
 	}
 
-	return list, nil
-}
+	var (
+		dumper    *pointDumper
+		closeDump func() error
+	)
+
+	if *dumpNormalizedFlag {
+		// The points are streamed while processing, so unlike the regular output the file is created upfront
+		dumpOut, closeDumpOut, err := createOutput(*outputFlag)
+		if err != nil {
+			return err
+		}
+		defer closeDumpOut()
+
+		dumper = newPointDumper(dumpOut)
+		closeDump = closeDumpOut
+		opts.Parsed = dumper.Dump
+	}
+
+	// The matching points are only needed for the map, so they are only collected then
+	var matches *matchCollector
+
+	if *formatFlag == "geojson" {
+		matches = &matchCollector{}
+		opts.Matched = matches.Collect
+	}
+
+	result, err := count(opts)
+	if err != nil {
+		return err
+	}
+
+	daysInTheOffice, stats := result.Days, result.Stats
+
+	filterDays(daysInTheOffice, *onlyFlag)
 
-func ParseTimelineInput(input io.Reader) ([]timelinePoint, error) {
-	type wrapper struct {
-		TimelineObjects []struct {
-			PlaceVisit *timelineVisitedPlace `json:"placeVisit"`
-		} `json:"timelineObjects"`
+	reportSkippedFiles(stats.Skipped)
 
-		SemanticSegments []semanticSegment `json:"semanticSegments"`
+	for _, format := range []office.Format{office.FormatSemanticSegments, office.FormatTimelineObjects, office.FormatTimelineObjectsLocation, office.FormatSegmentsArray, office.FormatRecords, office.FormatGPX} {
+		if count := stats.Formats[format]; count > 0 {
+			log.Debugf("Processed %d file(s) in format %s", count, format)
+		}
 	}
 
-	var w wrapper
+	// Without a location nothing has been counted, so only the parsed points are written
+	if _, ok := opts.Matcher.(noMatcher); ok && dumper != nil {
+		if err := dumper.Close(); err != nil {
+			return fmt.Errorf("could not write normalized points: %w", err)
+		}
+
+		return closeDump()
+	}
 
-	if err := json.NewDecoder(input).Decode(&w); err != nil {
-		return nil, fmt.Errorf("decoding JSON: %w", err)
+	if stats.Visits > 0 && stats.VisitsInRange == 0 {
+		log.Warnf("None of the %d visits found is within the given time range, check -start-date and -end-date", stats.Visits)
 	}
 
-	var result []timelinePoint
+	log.Debugf("%d visit(s) matched the office location", stats.MatchedVisits)
 
-	// Check for the newer semantic location history format exported from local device
-	if w.SemanticSegments != nil {
-		for _, entry := range w.SemanticSegments {
-			for _, point := range entry.TimelinePath {
-				// Parse the point
-				lat, long := parsePoint(point.Point)
+	if log.GetLevel() <= log.DebugLevel {
+		dates := daysInTheOffice.ToSlice()
+		sort.Strings(dates)
 
-				result = append(result, timelinePoint{
-					Latitude:  lat,
-					Longitude: long,
-					Start:     entry.StartTime,
-					End:       entry.EndTime,
-				})
+		for _, date := range dates {
+			if arrival, ok := stats.ArrivalPerDay[date]; ok {
+				log.Debugf("Arrived at the office on %s at %s", formatDate(date, dateFormat), arrival.Format("15:04"))
 			}
 		}
+	}
+
+	log.Infof("You have been in the office on %d day(s) of which %d have been working days.", len(daysInTheOffice), daysInTheOffice.CountWorkingDays())
+
+	// Without a start date the reports covering the time range start at the first visit instead
+	reportStartDate := startDate
+	if reportStartDate.IsZero() && !stats.FirstVisit.IsZero() {
+		reportStartDate = stats.FirstVisit.In(timezone)
+	}
 
-		return result, nil
+	var workingDays []time.Time
+	if !reportStartDate.IsZero() {
+		workingDays = cal.WorkingDays(reportStartDate, endDate)
 	}
 
-	// Remove nil entries, i.e. entries that are not place visits but activity segments or something else
-	for _, entry := range w.TimelineObjects {
-		if entry.PlaceVisit == nil {
-			continue
+	// With -only weekend no working days are left, absence on them would only reflect the filter
+	if *onlyFlag != "weekend" {
+		absent := 0
+
+		for _, day := range workingDays {
+			if _, ok := daysInTheOffice[day.Format("2006-01-02")]; !ok {
+				absent++
+			}
 		}
 
-		// Google removed these two fields at some point, so we simply take the second best option.
-		// See below.
-		if entry.PlaceVisit.CenterLatE7 == 0 || entry.PlaceVisit.CenterLngE7 == 0 {
-			entry.PlaceVisit.CenterLatE7 = entry.PlaceVisit.Location.LatitudeE7
-			entry.PlaceVisit.CenterLngE7 = entry.PlaceVisit.Location.LongitudeE7
+		log.Infof("You were absent from the office on %d of %d working days.", absent, len(workingDays))
+
+		if len(workingDays) > 0 {
+			present := len(workingDays) - absent
+			log.Infof("You have been in the office on %d of %d working days (%.0f%%).", present, len(workingDays), 100*float64(present)/float64(len(workingDays)))
 		}
+	}
 
-		place := entry.PlaceVisit
+	if dumper != nil {
+		if err := dumper.Close(); err != nil {
+			return fmt.Errorf("could not write normalized points: %w", err)
+		}
 
-		result = append(result, timelinePoint{
-			Latitude:  float64(place.CenterLatE7) / 1e7,
-			Longitude: float64(place.CenterLngE7) / 1e7,
-			Start:     place.Duration.Start,
-			End:       place.Duration.End,
-		})
+		return closeDump()
+	}
+
+	// The file is only created once the input has been processed, so failures do not leave an empty file behind
+	out, closeOutput, err := createOutput(*outputFlag)
+	if err != nil {
+		return err
+	}
+	defer closeOutput()
+
+	switch *formatFlag {
+	case "json":
+		// The summary above is logged to stderr, so stdout only contains the JSON document
+		if err := writeJSON(out, daysInTheOffice, dateFormat); err != nil {
+			return fmt.Errorf("could not write JSON: %w", err)
+		}
+	case "csv":
+		if err := writeCSV(out, daysInTheOffice, *csvHeaderFlag, dateFormat); err != nil {
+			return fmt.Errorf("could not write CSV: %w", err)
+		}
+	case "ics":
+		if err := writeICS(out, daysInTheOffice, cal); err != nil {
+			return fmt.Errorf("could not write iCalendar: %w", err)
+		}
+	case "geojson":
+		if err := writeGeoJSON(out, matches.matches, daysInTheOffice, locations, geofence); err != nil {
+			return fmt.Errorf("could not write GeoJSON: %w", err)
+		}
+	case "markdown":
+		// Without -group-by the table lists months, which is what most reports are about
+		period := *groupByFlag
+		if groupKey == nil {
+			period, groupKey = "month", groupKeys["month"]
+		}
+
+		groups, err := groupDays(daysInTheOffice, groupKey)
+		if err != nil {
+			return fmt.Errorf("could not group days: %w", err)
+		}
+
+		if err := writeMarkdown(out, groups, strings.ToUpper(period[:1])+period[1:]); err != nil {
+			return fmt.Errorf("could not write Markdown: %w", err)
+		}
+	case "heatmap":
+		if !reportStartDate.IsZero() {
+			writeHeatmap(out, daysInTheOffice, cal, reportStartDate, endDate)
+		}
+	default:
+		if groupKey != nil {
+			groups, err := groupDays(daysInTheOffice, groupKey)
+			if err != nil {
+				return fmt.Errorf("could not group days: %w", err)
+			}
+
+			printGroups(out, groups)
+		}
+
+		if *targetPerWeekFlag > 0 && !reportStartDate.IsZero() {
+			weeks := compliance(daysInTheOffice, reportStartDate, endDate, *targetPerWeekFlag, groupKeys["week"])
+			printWeeklyCompliance(out, weeks, *targetPerWeekFlag)
+		}
+
+		if anchors.Cardinality() > 0 {
+			printAnchorCompliance(out, anchorCompliance(daysInTheOffice, workingDays, anchors))
+		}
+
+		if reports.Contains("weekday") {
+			if err := printWeekdayStats(out, daysInTheOffice); err != nil {
+				return fmt.Errorf("could not print weekday statistics: %w", err)
+			}
+		}
+
+		if reports.Contains("streak") {
+			printStreakStats(out, daysInTheOffice, workingDays, dateFormat)
+		}
+
+		if reports.Contains("hours") {
+			printHoursStats(out, daysInTheOffice, stats.DurationPerDay)
+		}
+
+		if reports.Contains("locations") {
+			printLocationStats(out, daysInTheOffice, stats.LocationsPerDay, dateFormat)
+		}
+
+		if reports.Contains("coverage") {
+			printCoverageStats(out, stats.DatesWithData, workingDays, dateFormat)
+		}
+
+		if *printDatesFlag {
+			// The distances are only printed for auditing borderline days in verbose mode
+			var distancePerDay map[string]float64
+			if *verboseFlag {
+				distancePerDay = stats.DistancePerDay
+			}
+
+			printDates(out, daysInTheOffice, cal, stats.PlacesPerDay, distancePerDay, unitOf(*toleranceFlag), dateFormat)
+		}
+	}
+
+	if err := closeOutput(); err != nil {
+		return err
+	}
+
+	// The check comes last, so the regular output is written even if it fails
+	if *requirePerMonthFlag > 0 && !reportStartDate.IsZero() {
+		var missed []string
+
+		for _, month := range compliance(daysInTheOffice, reportStartDate, endDate, *requirePerMonthFlag, groupKeys["month"]) {
+			// The target applies to whole months, a partially covered one cannot be judged
+			if month.Partial {
+				log.Debugf("%s: not checked as the month is only partially within the time range", month.Period)
+				continue
+			}
+
+			if !month.MetTarget {
+				log.Warnf("%s: only %d of %d required working day(s) in the office", month.Period, month.WorkingDays, *requirePerMonthFlag)
+				missed = append(missed, month.Period)
+			}
+		}
+
+		if len(missed) > 0 {
+			return fmt.Errorf("required %d working day(s) in the office per month, missed in %s", *requirePerMonthFlag, strings.Join(missed, ", "))
+		}
+	}
+
+	return nil
+}
+
+// createOutput returns stdout, or the created file if a file name is given. The returned function closes the file and
+// may be called multiple times.
+func createOutput(fileName string) (io.Writer, func() error, error) {
+	if fileName == "" {
+		return os.Stdout, func() error { return nil }, nil
+	}
+
+	file, err := os.Create(fileName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not create output file: %w", err)
+	}
+
+	closed := false
+
+	closeFile := func() error {
+		if closed {
+			return nil
+		}
+
+		closed = true
+
+		if err := file.Close(); err != nil {
+			return fmt.Errorf("could not close output file: %w", err)
+		}
+
+		return nil
+	}
+
+	return file, closeFile, nil
+}
+
+// filterDays removes the days not matching the value of -only, i.e. non-working days for "working" and working days
+// for "weekend", which includes holidays
+func filterDays(daysInTheOffice office.DayMap, only string) {
+	if only == "" {
+		return
+	}
+
+	for date, isWorkingDay := range daysInTheOffice {
+		if isWorkingDay != (only == "working") {
+			delete(daysInTheOffice, date)
+		}
+	}
+}
+
+// reportSkippedFiles logs all skipped files at once along with the number of files per reason, as the individual log
+// lines are easy to miss in between the others
+func reportSkippedFiles(skipped []office.SkippedFile) {
+	if len(skipped) == 0 {
+		return
+	}
+
+	sort.Slice(skipped, func(i, j int) bool {
+		return skipped[i].Name < skipped[j].Name
+	})
+
+	countPerReason := make(map[office.SkipReason]int)
+
+	for _, file := range skipped {
+		log.Warn("Skipped file", "file", file.Name, "reason", file.Reason)
+		countPerReason[file.Reason]++
+	}
+
+	var counts []string
+
+	for _, reason := range []office.SkipReason{office.SkipReasonOpen, office.SkipReasonParse, office.SkipReasonNoTimelineData, office.SkipReasonNoPoints} {
+		if count := countPerReason[reason]; count > 0 {
+			counts = append(counts, fmt.Sprintf("%d %s", count, reason))
+		}
 	}
 
-	return result, nil
+	log.Warnf("%d file(s) have been skipped: %s", len(skipped), strings.Join(counts, ", "))
 }
 
-func parsePoint(value string) (float64, float64) {
-	// "51.6503959°, 5.0492413°"
-	coords := strings.Split(strings.ReplaceAll(value, "°", ""), ", ")
+// printProgress overwrites the current line of stderr with the number of processed files
+func printProgress(done, total int) {
+	fmt.Fprintf(os.Stderr, "\rProcessed %d/%d file(s)", done, total)
 
-	lat, _ := strconv.ParseFloat(coords[0], 64)
-	long, _ := strconv.ParseFloat(coords[1], 64)
+	if done == total {
+		fmt.Fprintln(os.Stderr)
+	}
+}
 
-	return lat, long
+// isTerminal reports whether the file is a terminal rather than e.g. a pipe or a regular file
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
 }
 
-type timelinePoint struct {
-	Latitude  float64
-	Longitude float64
+// listInputFiles returns the files of all input directories, see listFilesRecursively
+func listInputFiles(inputDirs, patterns, ignore []string, followSymlinks bool) ([]string, error) {
+	var list []string
+
+	for _, inputDir := range inputDirs {
+		fileNames, err := listFilesRecursively(inputDir, patterns, ignore, followSymlinks)
+		if err != nil {
+			return nil, err
+		}
 
-	Start time.Time
-	End   time.Time
+		list = append(list, fileNames...)
+	}
+
+	return list, nil
 }
 
-type timelineVisitedPlace struct {
-	Location struct {
-		LatitudeE7  int    `json:"latitudeE7"`
-		LongitudeE7 int    `json:"longitudeE7"`
-		Address     string `json:"address"`
-		Name        string `json:"name"`
-	} `json:"location"`
-	Duration struct {
-		Start time.Time `json:"startTimestamp"`
-		End   time.Time `json:"endTimestamp"`
-	} `json:"duration"`
-	VisitConfidence int `json:"visitConfidence"`
-	// It seems like Google removed these two fields on the 7th of February 2024 as they don't show up in records
-	// after this date.
-	CenterLatE7 int `json:"centerLatE7"`
-	CenterLngE7 int `json:"centerLngE7"`
+// listFilesRecursively returns all files within the directory and its subdirectories with a name matching any of the
+// patterns, skipping files and directories matching any of the ignore patterns
+func listFilesRecursively(inputDir string, patterns, ignore []string, followSymlinks bool) ([]string, error) {
+	var list []string
+
+	// Directories are tracked by their resolved path, so following symlinks never ends up in a cycle
+	visited := mapset.NewThreadUnsafeSet[string]()
+
+	var readDir func(string) error
+	readDir = func(dir string) error {
+		if followSymlinks {
+			resolved, err := filepath.EvalSymlinks(dir)
+			if err != nil {
+				return fmt.Errorf("could not resolve directory %s: %w", dir, err)
+			}
+
+			if !visited.Add(resolved) {
+				log.Debug("Skipping directory which has already been read", "dir", dir, "resolved", resolved)
+				return nil
+			}
+		}
+
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return fmt.Errorf("could not read directory %s: %w", dir, err)
+		}
+
+		for _, entry := range entries {
+			fullPath := path.Join(dir, entry.Name())
+
+			if rel, err := filepath.Rel(inputDir, fullPath); err == nil && isIgnored(filepath.ToSlash(rel), ignore) {
+				continue
+			}
+
+			isDir := entry.IsDir()
+
+			// Symlinks may point outside of the input directory or to one of its parents
+			if entry.Type()&os.ModeSymlink != 0 {
+				if !followSymlinks {
+					log.Warn("Skipping symlink, pass -follow-symlinks to read it", "path", fullPath)
+					continue
+				}
+
+				info, err := os.Stat(fullPath)
+				if err != nil {
+					log.Warn("Skipping broken symlink", "path", fullPath, "err", err)
+					continue
+				}
+
+				isDir = info.IsDir()
+			}
+
+			if isDir {
+				err := readDir(fullPath)
+				if err != nil {
+					return err
+				}
+			} else if matchesAny(entry.Name(), patterns) {
+				list = append(list, fullPath)
+			}
+		}
+
+		return nil
+	}
+
+	if _, err := os.Stat(inputDir); errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("input directory %s does not exist, check -input-dir", inputDir)
+	}
+
+	if err := readDir(inputDir); err != nil {
+		return nil, err
+	}
+
+	// An empty result is most likely a mistake, e.g. the wrong directory or patterns not matching the export
+	if len(list) == 0 {
+		return nil, fmt.Errorf("input directory %s contains no files matching %s, check -input-dir and -pattern", inputDir, strings.Join(patterns, ","))
+	}
+
+	// Files are processed in a stable order regardless of how the filesystem orders directory entries
+	sort.Strings(list)
+
+	return list, nil
 }
 
-type semanticSegment struct {
-	StartTime    time.Time `json:"startTime"`
-	EndTime      time.Time `json:"endTime"`
-	TimelinePath []struct {
-		Point string    `json:"point"`
-		Time  time.Time `json:"time"`
-	} `json:"timelinePath"`
+func matchesAny(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		// The patterns have been validated before, so errors can be ignored
+		if matched, _ := filepath.Match(pattern, name); matched {
+			return true
+		}
+	}
+
+	return false
 }
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestListFilesRecursivelySymlinks(t *testing.T) {
+	dir := t.TempDir()
+	outside := t.TempDir()
+
+	for _, fileName := range []string{filepath.Join(dir, "timeline.json"), filepath.Join(outside, "other.json")} {
+		if err := os.WriteFile(fileName, []byte("{}"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := os.Symlink(dir, filepath.Join(dir, "loop")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	if err := os.Symlink(outside, filepath.Join(dir, "outside")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name           string
+		followSymlinks bool
+		want           []string
+	}{
+		{
+			name: "skipping symlinks",
+			want: []string{filepath.Join(dir, "timeline.json")},
+		},
+		{
+			name:           "following symlinks",
+			followSymlinks: true,
+			want:           []string{filepath.Join(dir, "outside", "other.json"), filepath.Join(dir, "timeline.json")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := listFilesRecursively(dir, []string{"*.json"}, nil, tt.followSymlinks)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package office
+
+import (
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// cacheVersion has to be increased whenever Timeline or how it is parsed changes, so outdated entries are not used
+const cacheVersion = 1
+
+// cache stores the parsed timelines of files in a directory, so later runs do not have to parse them again
+type cache struct {
+	dir string
+}
+
+// cacheEntry is the parsed timeline of a file along with what is used to tell whether the file has changed since
+type cacheEntry struct {
+	Version  int
+	ModTime  time.Time
+	Size     int64
+	Timeline Timeline
+}
+
+// path returns the file the timeline of fileName is cached in. The parse options changing which points are parsed are
+// part of the key, those only affecting log output like RedactCoords are not.
+func (c cache) path(fileName string, opts ParseOptions) string {
+	if abs, err := filepath.Abs(fileName); err == nil {
+		fileName = abs
+	}
+
+	key := fmt.Sprintf("%s\x00activities=%t\x00location=%t", fileName, opts.IncludeActivities, opts.PreferLocation)
+	hash := sha256.Sum256([]byte(key))
+
+	return filepath.Join(c.dir, hex.EncodeToString(hash[:])+".gob")
+}
+
+// load returns the cached timeline of the file, it reports false if there is none, it was written by a different
+// version or the file has changed since
+func (c cache) load(fileName string, info os.FileInfo, opts ParseOptions) (Timeline, bool) {
+	file, err := os.Open(c.path(fileName, opts))
+	if err != nil {
+		return Timeline{}, false
+	}
+	defer file.Close()
+
+	var entry cacheEntry
+	if err := gob.NewDecoder(file).Decode(&entry); err != nil {
+		return Timeline{}, false
+	}
+
+	if entry.Version != cacheVersion || !entry.ModTime.Equal(info.ModTime()) || entry.Size != info.Size() {
+		return Timeline{}, false
+	}
+
+	return entry.Timeline, true
+}
+
+// store caches the timeline of the file, replacing an outdated entry. The entry is written to a temporary file first,
+// so an interrupted run never leaves a truncated entry behind.
+func (c cache) store(fileName string, info os.FileInfo, opts ParseOptions, timeline Timeline) error {
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return fmt.Errorf("creating cache directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(c.dir, "*.tmp")
+	if err != nil {
+		return fmt.Errorf("creating cache file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	entry := cacheEntry{
+		Version:  cacheVersion,
+		ModTime:  info.ModTime(),
+		Size:     info.Size(),
+		Timeline: timeline,
+	}
+
+	if err := gob.NewEncoder(tmp).Encode(entry); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing cache file: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing cache file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), c.path(fileName, opts)); err != nil {
+		return fmt.Errorf("renaming cache file: %w", err)
+	}
+
+	return nil
+}
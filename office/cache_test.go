@@ -0,0 +1,69 @@
+package office
+
+import (
+	"encoding/gob"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCacheVersion(t *testing.T) {
+	dir := t.TempDir()
+	c := cache{dir: filepath.Join(dir, "cache")}
+
+	fileName := filepath.Join(dir, "timeline.json")
+	if err := os.WriteFile(fileName, []byte(`{"timelineObjects":[]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	timeline := Timeline{
+		Format: FormatTimelineObjects,
+		Points: []TimelinePoint{{Latitude: 48.1794935, Longitude: 11.5858037, Start: time.Date(2024, 3, 4, 8, 0, 0, 0, time.UTC)}},
+	}
+
+	if err := c.store(fileName, info, ParseOptions{}, timeline); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := c.load(fileName, info, ParseOptions{}); !ok {
+		t.Fatal("expected a cached timeline")
+	}
+
+	if _, ok := c.load(fileName, info, ParseOptions{PreferLocation: true}); ok {
+		t.Error("expected no cached timeline for different parse options")
+	}
+
+	if _, ok := c.load(fileName, info, ParseOptions{IncludeActivities: true}); ok {
+		t.Error("expected no cached timeline when including activities")
+	}
+
+	// Redaction only changes log output, so the entry can be used either way
+	if _, ok := c.load(fileName, info, ParseOptions{RedactCoords: true}); !ok {
+		t.Error("expected a cached timeline regardless of redaction")
+	}
+
+	// Overwrite the entry with one written by another version
+	file, err := os.Create(c.path(fileName, ParseOptions{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entry := cacheEntry{Version: cacheVersion + 1, ModTime: info.ModTime(), Size: info.Size(), Timeline: timeline}
+	if err := gob.NewEncoder(file).Encode(entry); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := c.load(fileName, info, ParseOptions{}); ok {
+		t.Error("expected no cached timeline for a different version")
+	}
+}
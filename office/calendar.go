@@ -0,0 +1,54 @@
+package office
+
+import (
+	"time"
+
+	mapset "github.com/deckarep/golang-set/v2"
+)
+
+// Calendar decides which days are working days
+type Calendar struct {
+	// Weekend contains the days which are not working days, may be nil
+	Weekend mapset.Set[time.Weekday]
+	// Holidays contains dates formatted as 2006-01-02, may be nil
+	Holidays mapset.Set[string]
+}
+
+func (c Calendar) IsWorkingDay(t time.Time) bool {
+	return !c.IsWeekend(t.Weekday()) && !c.IsHoliday(t.Format("2006-01-02"))
+}
+
+func (c Calendar) IsWeekend(weekday time.Weekday) bool {
+	return c.Weekend != nil && c.Weekend.Contains(weekday)
+}
+
+func (c Calendar) IsHoliday(date string) bool {
+	return c.Holidays != nil && c.Holidays.Contains(date)
+}
+
+// WorkingDays returns all working days within [start, end]
+func (c Calendar) WorkingDays(start, end time.Time) []time.Time {
+	var result []time.Time
+
+	for _, day := range Days(start, end) {
+		if c.IsWorkingDay(day) {
+			result = append(result, day)
+		}
+	}
+
+	return result
+}
+
+// Days returns midnight of each day within [start, end], in the location of start
+func Days(start, end time.Time) []time.Time {
+	var result []time.Time
+
+	// Start at midnight, so the last day is included regardless of the time of day of start and end
+	first := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+
+	for day := first; !day.After(end); day = day.AddDate(0, 0, 1) {
+		result = append(result, day)
+	}
+
+	return result
+}
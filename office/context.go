@@ -0,0 +1,20 @@
+package office
+
+import (
+	"context"
+	"io"
+)
+
+// contextReader fails reading once the context is done, so processing stops in the middle of large files
+type contextReader struct {
+	ctx    context.Context
+	reader io.Reader
+}
+
+func (r contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	return r.reader.Read(p)
+}
@@ -0,0 +1,73 @@
+package office
+
+import (
+	"sort"
+	"time"
+)
+
+// DayMap maps a stringified date to a boolean indicating whether it was a working day.
+// Like any map it is not safe for concurrent writes, concurrent workers each fill their own map and merge them.
+type DayMap map[string]bool
+
+// Add adds the date of t, isWorkingDay is usually Calendar.IsWorkingDay
+func (d DayMap) Add(t time.Time, isWorkingDay func(time.Time) bool) {
+	date := t.Format("2006-01-02")
+	d[date] = isWorkingDay(t)
+}
+
+// Merge adds all days of the other map. A date is a working day if it is one in either map, both maps usually agree as
+// it only depends on the calendar.
+func (d DayMap) Merge(other DayMap) {
+	for date, isWorkingDay := range other {
+		d[date] = d[date] || isWorkingDay
+	}
+}
+
+func (d DayMap) ToSlice() []string {
+	slice := make([]string, 0, len(d))
+
+	for key := range d {
+		slice = append(slice, key)
+	}
+
+	return slice
+}
+
+func (d DayMap) CountWorkingDays() int {
+	count := 0
+
+	for _, isWorkingDay := range d {
+		if isWorkingDay {
+			count++
+		}
+	}
+
+	return count
+}
+
+// Day is a date spent in the office
+type Day struct {
+	// Date is midnight of the day in UTC
+	Date         time.Time
+	IsWorkingDay bool
+}
+
+// MatchedDays returns the days sorted ascending, keys which are not formatted as 2006-01-02 are left out
+func (d DayMap) MatchedDays() []Day {
+	days := make([]Day, 0, len(d))
+
+	for key, isWorkingDay := range d {
+		date, err := time.Parse("2006-01-02", key)
+		if err != nil {
+			continue
+		}
+
+		days = append(days, Day{Date: date, IsWorkingDay: isWorkingDay})
+	}
+
+	sort.Slice(days, func(i, j int) bool {
+		return days[i].Date.Before(days[j].Date)
+	})
+
+	return days
+}
@@ -0,0 +1,50 @@
+package office
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDayMapMerge(t *testing.T) {
+	tests := []struct {
+		name  string
+		days  DayMap
+		other DayMap
+		want  DayMap
+	}{
+		{
+			name:  "disjoint",
+			days:  DayMap{"2024-03-01": true, "2024-03-02": false},
+			other: DayMap{"2024-03-04": true},
+			want:  DayMap{"2024-03-01": true, "2024-03-02": false, "2024-03-04": true},
+		},
+		{
+			name:  "overlapping",
+			days:  DayMap{"2024-03-01": true, "2024-03-02": false},
+			other: DayMap{"2024-03-01": true, "2024-03-02": false, "2024-03-04": true},
+			want:  DayMap{"2024-03-01": true, "2024-03-02": false, "2024-03-04": true},
+		},
+		{
+			name:  "disagreeing on working days",
+			days:  DayMap{"2024-03-01": false, "2024-03-04": true},
+			other: DayMap{"2024-03-01": true, "2024-03-04": false},
+			want:  DayMap{"2024-03-01": true, "2024-03-04": true},
+		},
+		{
+			name:  "empty",
+			days:  DayMap{},
+			other: DayMap{"2024-03-02": false},
+			want:  DayMap{"2024-03-02": false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.days.Merge(tt.other)
+
+			if !reflect.DeepEqual(tt.days, tt.want) {
+				t.Errorf("got %v, want %v", tt.days, tt.want)
+			}
+		})
+	}
+}
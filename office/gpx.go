@@ -0,0 +1,99 @@
+package office
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+// FormatGPX is a GPX file as written by GPS loggers, only its track points are used
+const FormatGPX Format = "gpx"
+
+// gpxTrackPoint is a <trkpt> element of a GPX track
+type gpxTrackPoint struct {
+	Latitude  float64   `xml:"lat,attr"`
+	Longitude float64   `xml:"lon,attr"`
+	Time      time.Time `xml:"time"`
+}
+
+// ParseGPXInput returns the track points of a GPX file as points without duration, like the raw location history.
+// Like ParseTimelineInput the input is decoded element by element, so large tracks do not have to be held in memory.
+func ParseGPXInput(input io.Reader) (Timeline, error) {
+	return parseGPX(input, log.Default())
+}
+
+// parseGPX parses a GPX file, skipped track points are logged to the logger
+func parseGPX(input io.Reader, logger *log.Logger) (Timeline, error) {
+	decoder := xml.NewDecoder(input)
+
+	timeline := Timeline{
+		Format: FormatGPX,
+	}
+
+	hasRoot := false
+	withoutTime := 0
+
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return Timeline{}, fmt.Errorf("decoding XML: %w", err)
+		}
+
+		element, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		if !hasRoot {
+			if element.Name.Local != "gpx" {
+				return Timeline{}, fmt.Errorf("decoding XML: expected a gpx element, got %s", element.Name.Local)
+			}
+
+			hasRoot = true
+
+			continue
+		}
+
+		if element.Name.Local != "trkpt" {
+			continue
+		}
+
+		var point gpxTrackPoint
+
+		if err := decoder.DecodeElement(&point, &element); err != nil {
+			return Timeline{}, fmt.Errorf("decoding track point: %w", err)
+		}
+
+		if point.Time.IsZero() {
+			withoutTime++
+			continue
+		}
+
+		timeline.Points = append(timeline.Points, TimelinePoint{
+			Latitude:    point.Latitude,
+			Longitude:   point.Longitude,
+			Start:       point.Time,
+			End:         point.Time,
+			Confidence:  NoConfidence,
+			Probability: NoProbability,
+		})
+	}
+
+	if !hasRoot {
+		return Timeline{}, ErrNoTimelineData
+	}
+
+	if withoutTime > 0 {
+		logger.Warnf("Skipped %d track point(s) without time", withoutTime)
+	}
+
+	return timeline, nil
+}
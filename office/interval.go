@@ -0,0 +1,80 @@
+package office
+
+import (
+	"sort"
+	"time"
+)
+
+// interval is the time span of a visit
+type interval struct {
+	Start time.Time
+	End   time.Time
+}
+
+// mergedDuration returns the time covered by the intervals. Google sometimes splits a single stay into several visits
+// which overlap or abut, so overlapping and adjacent intervals are coalesced before summing them up.
+func mergedDuration(intervals []interval) time.Duration {
+	if len(intervals) == 0 {
+		return 0
+	}
+
+	sorted := make([]interval, len(intervals))
+	copy(sorted, intervals)
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Start.Before(sorted[j].Start)
+	})
+
+	var total time.Duration
+
+	current := sorted[0]
+
+	for _, next := range sorted[1:] {
+		if next.Start.After(current.End) {
+			total += current.End.Sub(current.Start)
+			current = next
+
+			continue
+		}
+
+		if next.End.After(current.End) {
+			current.End = next.End
+		}
+	}
+
+	return total + current.End.Sub(current.Start)
+}
+
+// splitByDay splits the interval at each midnight in the location of its start and returns the part on each day
+func (i interval) splitByDay() []interval {
+	var parts []interval
+
+	start := i.Start
+
+	for {
+		midnight := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location()).AddDate(0, 0, 1)
+		if !i.End.After(midnight) {
+			return append(parts, interval{Start: start, End: i.End})
+		}
+
+		parts = append(parts, interval{Start: start, End: midnight})
+		start = midnight
+	}
+}
+
+// earliestStart returns the earliest start of the intervals, it reports false if there are none
+func earliestStart(intervals []interval) (time.Time, bool) {
+	if len(intervals) == 0 {
+		return time.Time{}, false
+	}
+
+	earliest := intervals[0].Start
+
+	for _, i := range intervals[1:] {
+		if i.Start.Before(earliest) {
+			earliest = i.Start
+		}
+	}
+
+	return earliest, true
+}
@@ -0,0 +1,116 @@
+package office
+
+import (
+	"testing"
+	"time"
+)
+
+// at returns the time on March 4th 2024 in UTC
+func at(hour, minute int) time.Time {
+	return time.Date(2024, 3, 4, hour, minute, 0, 0, time.UTC)
+}
+
+func TestMergedDuration(t *testing.T) {
+	tests := []struct {
+		name      string
+		intervals []interval
+		want      time.Duration
+	}{
+		{
+			name: "none",
+			want: 0,
+		},
+		{
+			name:      "single",
+			intervals: []interval{{at(8, 0), at(12, 0)}},
+			want:      4 * time.Hour,
+		},
+		{
+			name:      "overlapping",
+			intervals: []interval{{at(8, 0), at(12, 0)}, {at(11, 0), at(14, 0)}},
+			want:      6 * time.Hour,
+		},
+		{
+			name:      "contained",
+			intervals: []interval{{at(8, 0), at(17, 0)}, {at(10, 0), at(11, 0)}},
+			want:      9 * time.Hour,
+		},
+		{
+			name:      "adjacent",
+			intervals: []interval{{at(8, 0), at(12, 0)}, {at(12, 0), at(13, 30)}},
+			want:      5*time.Hour + 30*time.Minute,
+		},
+		{
+			name:      "disjoint",
+			intervals: []interval{{at(8, 0), at(12, 0)}, {at(13, 0), at(17, 0)}},
+			want:      8 * time.Hour,
+		},
+		{
+			name:      "unsorted mix",
+			intervals: []interval{{at(13, 0), at(17, 0)}, {at(8, 0), at(10, 0)}, {at(9, 0), at(12, 0)}, {at(17, 0), at(18, 0)}},
+			want:      9 * time.Hour,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mergedDuration(tt.intervals); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitByDay(t *testing.T) {
+	day := func(d, hour, minute int) time.Time {
+		return time.Date(2024, 3, d, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name  string
+		visit interval
+		want  []interval
+	}{
+		{
+			name:  "within a day",
+			visit: interval{day(4, 8, 0), day(4, 17, 0)},
+			want:  []interval{{day(4, 8, 0), day(4, 17, 0)}},
+		},
+		{
+			name:  "spanning midnight",
+			visit: interval{day(4, 22, 0), day(5, 1, 0)},
+			want:  []interval{{day(4, 22, 0), day(5, 0, 0)}, {day(5, 0, 0), day(5, 1, 0)}},
+		},
+		{
+			name:  "ending exactly at midnight",
+			visit: interval{day(4, 22, 0), day(5, 0, 0)},
+			want:  []interval{{day(4, 22, 0), day(5, 0, 0)}},
+		},
+		{
+			name:  "spanning several days",
+			visit: interval{day(4, 12, 0), day(6, 9, 30)},
+			want:  []interval{{day(4, 12, 0), day(5, 0, 0)}, {day(5, 0, 0), day(6, 0, 0)}, {day(6, 0, 0), day(6, 9, 30)}},
+		},
+		{
+			name:  "without duration",
+			visit: interval{day(4, 8, 0), day(4, 8, 0)},
+			want:  []interval{{day(4, 8, 0), day(4, 8, 0)}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.visit.splitByDay()
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d part(s), want %d: %v", len(got), len(tt.want), got)
+			}
+
+			for i := range tt.want {
+				if !got[i].Start.Equal(tt.want[i].Start) || !got[i].End.Equal(tt.want[i].End) {
+					t.Errorf("part %d: got %s - %s, want %s - %s", i, got[i].Start, got[i].End, tt.want[i].Start, tt.want[i].End)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package office
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/geo"
+)
+
+// Location is a point with a radius in meters around it, places within the radius are considered to be the location
+type Location struct {
+	// Point is given as longitude, latitude like everywhere in orb
+	Point     orb.Point
+	Tolerance float64
+	// Label is an optional human-readable name of the location, e.g. "HQ"
+	Label string
+}
+
+func (l Location) String() string {
+	if l.Label != "" {
+		return l.Label
+	}
+
+	return fmt.Sprintf("%g,%g", l.Point.Lat(), l.Point.Lon())
+}
+
+// describe returns the location for log output, coordinates are rounded to 2 decimal places (about 1km) if redact is
+// set
+func (l Location) describe(redact bool) string {
+	if l.Label != "" || !redact {
+		return l.String()
+	}
+
+	return fmt.Sprintf("%.2f,%.2f", l.Point.Lat(), l.Point.Lon())
+}
+
+// Contains reports whether the given point lies within the tolerance around the location
+func (l Location) Contains(p orb.Point) bool {
+	return geo.DistanceHaversine(l.Point, p) <= l.Tolerance
+}
+
+// Validate checks that the coordinates are within range and the tolerance is positive
+func (l Location) Validate() error {
+	var errs []error
+
+	lat, lon := l.Point.Lat(), l.Point.Lon()
+
+	if lat < -90 || lat > 90 {
+		err := fmt.Errorf("latitude %g is not within [-90, 90]", lat)
+
+		if lon >= -90 && lon <= 90 {
+			err = fmt.Errorf("%w, latitude and longitude might be swapped", err)
+		}
+
+		errs = append(errs, err)
+	}
+
+	if lon < -180 || lon > 180 {
+		errs = append(errs, fmt.Errorf("longitude %g is not within [-180, 180]", lon))
+	}
+
+	if l.Tolerance <= 0 {
+		errs = append(errs, fmt.Errorf("tolerance %g has to be greater than 0", l.Tolerance))
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,54 @@
+package office
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/paulmach/orb"
+)
+
+var (
+	amsterdam = orb.Point{4.9041, 52.3676}
+	rotterdam = TimelinePoint{Latitude: 51.9244, Longitude: 4.4777}
+)
+
+// The distance between Amsterdam and Rotterdam is about 57km, with latitude and longitude swapped it would be about
+// 68km
+func TestDistanceAmsterdamRotterdam(t *testing.T) {
+	_, distance, ok := RadiusMatcher{{Point: amsterdam, Tolerance: 100}}.Nearest(rotterdam)
+	if !ok {
+		t.Fatal("expected a nearest location")
+	}
+
+	if math.Abs(distance-57000) > 500 {
+		t.Errorf("got %.0fm between Amsterdam and Rotterdam, want about 57km", distance)
+	}
+
+	p := orb.Point{rotterdam.Longitude, rotterdam.Latitude}
+
+	if !(Location{Point: amsterdam, Tolerance: 58000}).Contains(p) {
+		t.Error("expected Rotterdam to be within 58km of Amsterdam")
+	}
+
+	if (Location{Point: amsterdam, Tolerance: 56000}).Contains(p) {
+		t.Error("expected Rotterdam not to be within 56km of Amsterdam")
+	}
+}
+
+func TestValidateSwapped(t *testing.T) {
+	// Sydney given as latitude, longitude instead of longitude, latitude
+	err := Location{Point: orb.Point{-33.8568, 151.2153}, Tolerance: 100}.Validate()
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	if !strings.Contains(err.Error(), "might be swapped") {
+		t.Errorf("expected a hint about swapped coordinates, got %q", err)
+	}
+
+	err = Location{Point: orb.Point{151.2153, -33.8568}, Tolerance: 100}.Validate()
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
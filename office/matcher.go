@@ -0,0 +1,95 @@
+package office
+
+import (
+	"math"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/geo"
+	"github.com/paulmach/orb/planar"
+)
+
+// Matcher decides whether a point of the timeline is considered a visit to the office
+type Matcher interface {
+	Matches(TimelinePoint) bool
+}
+
+// RadiusMatcher matches points within the tolerance around any of the locations
+type RadiusMatcher []Location
+
+func (m RadiusMatcher) Matches(point TimelinePoint) bool {
+	p := orb.Point{point.Longitude, point.Latitude}
+
+	// A visit might be within several locations, the day map makes sure the day is only counted once anyway
+	for _, loc := range m {
+		if loc.Contains(p) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Matching returns all locations containing the point
+func (m RadiusMatcher) Matching(point TimelinePoint) []Location {
+	p := orb.Point{point.Longitude, point.Latitude}
+
+	var result []Location
+
+	for _, loc := range m {
+		if loc.Contains(p) {
+			result = append(result, loc)
+		}
+	}
+
+	return result
+}
+
+// Nearest returns the location closest to the point and the distance to it in meters.
+// It reports false if there are no locations.
+func (m RadiusMatcher) Nearest(point TimelinePoint) (Location, float64, bool) {
+	p := orb.Point{point.Longitude, point.Latitude}
+
+	var (
+		nearest  Location
+		distance = math.Inf(1)
+	)
+
+	for _, loc := range m {
+		if d := geo.DistanceHaversine(loc.Point, p); d < distance {
+			nearest, distance = loc, d
+		}
+	}
+
+	return nearest, distance, len(m) > 0
+}
+
+// nearestLocator is implemented by matchers which know the closest location to a point, it is used for debug output
+type nearestLocator interface {
+	Nearest(TimelinePoint) (Location, float64, bool)
+}
+
+// locationsMatcher is implemented by matchers which know the locations a point is within, it is used to track the
+// locations visited per day
+type locationsMatcher interface {
+	Matching(TimelinePoint) []Location
+}
+
+// PolygonMatcher matches points within any of the polygons, e.g. loaded from a GeoJSON file
+type PolygonMatcher orb.MultiPolygon
+
+func (m PolygonMatcher) Matches(point TimelinePoint) bool {
+	return planar.MultiPolygonContains(orb.MultiPolygon(m), orb.Point{point.Longitude, point.Latitude})
+}
+
+// AndMatcher matches points which are matched by all of its matchers
+type AndMatcher []Matcher
+
+func (m AndMatcher) Matches(point TimelinePoint) bool {
+	for _, matcher := range m {
+		if !matcher.Matches(point) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,865 @@
+// Package office counts the days spent in the office based on a Google Maps timeline export.
+package office
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/charmbracelet/log"
+	mapset "github.com/deckarep/golang-set/v2"
+	"github.com/paulmach/orb"
+)
+
+// Options controls which visits are counted
+type Options struct {
+	StartDate time.Time
+	// EndDate is the end of the time range, the range is open-ended if it is zero
+	EndDate   time.Time
+	Locations []Location
+	// Geofence takes precedence over Locations if set
+	Geofence orb.MultiPolygon
+	// Matcher takes precedence over Geofence and Locations if set
+	Matcher Matcher
+	// MinDuration is the minimum time spent at a place, shorter visits are ignored
+	MinDuration time.Duration
+	// CoreRadius is the distance in meters around each of the Locations within which visits count regardless of
+	// MinDuration, so MinDuration only applies to visits between the core radius and the tolerance. Zero applies
+	// MinDuration to all visits.
+	CoreRadius float64
+	// MinConfidence is the minimum visit confidence, places without a confidence always pass
+	MinConfidence int
+	// MinProbability is the minimum probability of the visited place in the range [0, 1], places without a probability
+	// always pass
+	MinProbability float64
+	Calendar       Calendar
+	// IsWorkingDay decides whether a date is a working day if set, taking precedence over Calendar. It allows for
+	// calendars which cannot be expressed by weekend days and holidays, e.g. shift work. Calls may be concurrent.
+	IsWorkingDay func(time.Time) bool
+	// Timezone is used to determine the date of a visit, defaults to the local timezone
+	Timezone *time.Location
+	// CountSpannedDays counts every date a matching visit spans instead of only the date it started on
+	CountSpannedDays bool
+	// UseVisitTimezone determines the date of a visit using the offset of its timestamp instead of Timezone
+	UseVisitTimezone bool
+	Parse            ParseOptions
+	// SeenVisits is used to skip duplicate visits if set
+	SeenVisits mapset.Set[VisitKey]
+	// Concurrency is the number of files processed in parallel by CountDaysInOffice, defaults to the number of CPUs
+	Concurrency int
+	// MinPointsPerDay is the minimum number of matching visits or points on a day for it to be counted, defaults to 1
+	MinPointsPerDay int
+	// ExcludedLocations disqualifies visits within any of them, even if they match the office
+	ExcludedLocations []Location
+	// ExcludedDates contains dates formatted as 2006-01-02 which are never counted, may be nil
+	ExcludedDates mapset.Set[string]
+	// Parsed is called with all points of each input before any filtering if set, calls may be concurrent
+	Parsed func(name string, points []TimelinePoint)
+	// Matched is called with each matching visit or point and the date it is counted on if set, calls may be concurrent
+	Matched func(date string, point TimelinePoint)
+	// Progress is called after each processed file if set, calls are never concurrent
+	Progress func(done, total int)
+	// AllowNullIsland considers points at exactly (0,0), which are skipped by default as they usually stem from
+	// missing or unparsable coordinates
+	AllowNullIsland bool
+	// Strict fails processing if any file cannot be read or parsed instead of skipping it
+	Strict bool
+	// CacheDir is a directory the parsed timelines of files are stored in if set. Files which have not been modified
+	// since are not parsed again on later runs. Input read from zip archives or readers is never cached.
+	CacheDir string
+}
+
+func (o Options) validate() error {
+	if len(o.Locations) == 0 && o.Geofence == nil && o.Matcher == nil {
+		return errors.New("no location given, either Locations, Geofence or Matcher has to be set")
+	}
+
+	for _, loc := range o.Locations {
+		if err := loc.Validate(); err != nil {
+			return fmt.Errorf("location %s is invalid: %w", loc, err)
+		}
+	}
+
+	if o.CoreRadius < 0 {
+		return fmt.Errorf("core radius has to be positive, got %g", o.CoreRadius)
+	}
+
+	for _, loc := range o.ExcludedLocations {
+		if err := loc.Validate(); err != nil {
+			return fmt.Errorf("excluded location %s is invalid: %w", loc, err)
+		}
+	}
+
+	return nil
+}
+
+// matcher returns the matcher deciding which visits are counted
+func (o Options) matcher() Matcher {
+	if o.Matcher != nil {
+		return o.Matcher
+	}
+
+	if o.Geofence != nil {
+		return PolygonMatcher(o.Geofence)
+	}
+
+	return RadiusMatcher(o.Locations)
+}
+
+// inCore reports whether the point is within the core radius around any of the locations
+func (o Options) inCore(point TimelinePoint) bool {
+	if o.CoreRadius <= 0 {
+		return false
+	}
+
+	_, distance, ok := RadiusMatcher(o.Locations).Nearest(point)
+
+	return ok && distance <= o.CoreRadius
+}
+
+// isWorkingDay returns the function deciding whether a date is a working day
+func (o Options) isWorkingDay() func(time.Time) bool {
+	if o.IsWorkingDay != nil {
+		return o.IsWorkingDay
+	}
+
+	return o.Calendar.IsWorkingDay
+}
+
+func (o Options) timezone() *time.Location {
+	if o.Timezone == nil {
+		return time.Local
+	}
+
+	return o.Timezone
+}
+
+// localTime returns t in the timezone used to determine its date
+func (o Options) localTime(t time.Time) time.Time {
+	if o.UseVisitTimezone {
+		return t
+	}
+
+	return t.In(o.timezone())
+}
+
+// VisitKey identifies a visit by its start and its coordinates rounded to 4 decimal places, which is about 11 meters.
+// Coordinates of the same visit in different files may differ slightly.
+type VisitKey struct {
+	Start     int64
+	Latitude  float64
+	Longitude float64
+}
+
+func newVisitKey(place TimelinePoint) VisitKey {
+	return VisitKey{
+		Start:     place.Start.Unix(),
+		Latitude:  math.Round(place.Latitude*1e4) / 1e4,
+		Longitude: math.Round(place.Longitude*1e4) / 1e4,
+	}
+}
+
+// Stats counts the visits found while processing the input
+type Stats struct {
+	Visits int
+	// VisitsInRange is the number of visits (partially) within the time range, including duplicates
+	VisitsInRange int
+	// MatchedVisits is the number of visits passing all filters and matching the office
+	MatchedVisits int
+	// FirstVisit is the start of the earliest visit within the time range
+	FirstVisit time.Time
+	// SkippedFiles is the number of files that could not be processed
+	SkippedFiles int
+	// Skipped lists the skipped files along with the reason, in no particular order
+	Skipped []SkippedFile
+	// Formats counts the processed files per detected format
+	Formats map[Format]int
+	// DurationPerDay is the time covered by the matching visits per date, visits spanning midnight count towards the
+	// date they started on unless CountSpannedDays is set. Overlapping visits are merged, so time is only counted once.
+	// It is only set once all input has been processed.
+	DurationPerDay map[string]time.Duration
+	// ArrivalPerDay is the start of the earliest matching visit per date, in the timezone used to determine the date.
+	// It is only set once all input has been processed.
+	ArrivalPerDay map[string]time.Time
+	// DistancePerDay is the distance in meters of the matching visit closest to any of the locations per date.
+	// It is only set for matchers based on locations, i.e. not for a geofence.
+	DistancePerDay map[string]float64
+	// LocationsPerDay holds the names of the distinct locations matched per date.
+	// It is only set for matchers based on locations, i.e. not for a geofence.
+	LocationsPerDay map[string]mapset.Set[string]
+	// PlacesPerDay holds the names and addresses of the matched places per date, see TimelinePoint.Place.
+	// Dates without any known place are missing.
+	PlacesPerDay map[string]mapset.Set[string]
+	// DatesWithData holds the dates covered by any point within the time range, matching or not. Dates missing from it
+	// had no location data at all.
+	DatesWithData mapset.Set[string]
+
+	// matchesPerDay holds the matching visits or points per date
+	matchesPerDay map[string][]interval
+	// nearMisses holds the closest visit not matching the office per date, it is only collected in debug mode
+	nearMisses map[string]nearMiss
+}
+
+// skip logs why a file could not be processed and counts it
+func (s *Stats) skip(fileName string, err error) {
+	reason := skipReason(err)
+
+	s.SkippedFiles++
+	s.Skipped = append(s.Skipped, SkippedFile{
+		Name:   fileName,
+		Reason: reason,
+		Err:    err,
+	})
+
+	logger := log.With("file", fileName)
+
+	switch reason {
+	case SkipReasonNoTimelineData:
+		logger.Warn("Skipping file without timeline data, make sure to point to the location history")
+	case SkipReasonNoPoints:
+		logger.Warn("Skipping file without timeline points")
+	default:
+		logger.Error("Skipping file", "err", err)
+	}
+}
+
+// add accumulates the stats of another input
+func (s *Stats) add(other Stats) {
+	s.Visits += other.Visits
+	s.VisitsInRange += other.VisitsInRange
+	s.MatchedVisits += other.MatchedVisits
+	s.SkippedFiles += other.SkippedFiles
+	s.Skipped = append(s.Skipped, other.Skipped...)
+	s.observe(other.FirstVisit)
+
+	for format, count := range other.Formats {
+		if s.Formats == nil {
+			s.Formats = make(map[Format]int)
+		}
+
+		s.Formats[format] += count
+	}
+
+	for date, locations := range other.LocationsPerDay {
+		if s.LocationsPerDay == nil {
+			s.LocationsPerDay = make(map[string]mapset.Set[string])
+		}
+
+		if existing, ok := s.LocationsPerDay[date]; ok {
+			existing.Append(locations.ToSlice()...)
+		} else {
+			s.LocationsPerDay[date] = locations.Clone()
+		}
+	}
+
+	for date, places := range other.PlacesPerDay {
+		if s.PlacesPerDay == nil {
+			s.PlacesPerDay = make(map[string]mapset.Set[string])
+		}
+
+		if existing, ok := s.PlacesPerDay[date]; ok {
+			existing.Append(places.ToSlice()...)
+		} else {
+			s.PlacesPerDay[date] = places.Clone()
+		}
+	}
+
+	for date, distance := range other.DistancePerDay {
+		s.approach(date, distance)
+	}
+
+	if other.DatesWithData != nil {
+		if s.DatesWithData == nil {
+			s.DatesWithData = mapset.NewThreadUnsafeSet[string]()
+		}
+
+		s.DatesWithData.Append(other.DatesWithData.ToSlice()...)
+	}
+
+	for date, miss := range other.nearMisses {
+		s.miss(date, miss)
+	}
+
+	for date, visits := range other.matchesPerDay {
+		if s.matchesPerDay == nil {
+			s.matchesPerDay = make(map[string][]interval)
+		}
+
+		s.matchesPerDay[date] = append(s.matchesPerDay[date], visits...)
+	}
+}
+
+// match records a matching visit, or the part of it, on the date
+func (s *Stats) match(date string, visit interval) {
+	if s.matchesPerDay == nil {
+		s.matchesPerDay = make(map[string][]interval)
+	}
+
+	s.matchesPerDay[date] = append(s.matchesPerDay[date], visit)
+}
+
+// strictError returns the errors of all skipped files in strict mode.
+// Files without any points are not considered an error, e.g. a month without any visits.
+func (s *Stats) strictError(opts Options) error {
+	if !opts.Strict {
+		return nil
+	}
+
+	var errs []error
+
+	for _, skipped := range s.Skipped {
+		if skipped.Reason != SkipReasonNoPoints {
+			errs = append(errs, fmt.Errorf("%s: %w", skipped.Name, skipped.Err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// visitLocation records that the location has been visited on the date
+func (s *Stats) visitLocation(date string, loc Location) {
+	if s.LocationsPerDay == nil {
+		s.LocationsPerDay = make(map[string]mapset.Set[string])
+	}
+
+	if _, ok := s.LocationsPerDay[date]; !ok {
+		s.LocationsPerDay[date] = mapset.NewThreadUnsafeSet[string]()
+	}
+
+	s.LocationsPerDay[date].Add(loc.String())
+}
+
+// visitPlace records that the named place has been visited on the date
+func (s *Stats) visitPlace(date string, place string) {
+	if s.PlacesPerDay == nil {
+		s.PlacesPerDay = make(map[string]mapset.Set[string])
+	}
+
+	if _, ok := s.PlacesPerDay[date]; !ok {
+		s.PlacesPerDay[date] = mapset.NewThreadUnsafeSet[string]()
+	}
+
+	s.PlacesPerDay[date].Add(place)
+}
+
+// miss records a visit not matching the office on the date if it is the closest one so far
+func (s *Stats) miss(date string, miss nearMiss) {
+	if s.nearMisses == nil {
+		s.nearMisses = make(map[string]nearMiss)
+	}
+
+	if closest, ok := s.nearMisses[date]; !ok || miss.Distance < closest.Distance {
+		s.nearMisses[date] = miss
+	}
+}
+
+// approach records the distance of a matching visit on the date if it is the closest one so far
+func (s *Stats) approach(date string, distance float64) {
+	if s.DistancePerDay == nil {
+		s.DistancePerDay = make(map[string]float64)
+	}
+
+	if closest, ok := s.DistancePerDay[date]; !ok || distance < closest {
+		s.DistancePerDay[date] = distance
+	}
+}
+
+// cover records the dates the visit spans as having location data
+func (s *Stats) cover(visit interval) {
+	if s.DatesWithData == nil {
+		s.DatesWithData = mapset.NewThreadUnsafeSet[string]()
+	}
+
+	for _, part := range visit.splitByDay() {
+		s.DatesWithData.Add(part.Start.Format("2006-01-02"))
+	}
+}
+
+func (s *Stats) observe(start time.Time) {
+	if !start.IsZero() && (s.FirstVisit.IsZero() || start.Before(s.FirstVisit)) {
+		s.FirstVisit = start
+	}
+}
+
+// Result holds the days spent in the office along with statistics about the processed input
+type Result struct {
+	Days  DayMap
+	Stats Stats
+}
+
+// CountDaysInOffice processes the timeline files and returns all days with visits to the office.
+// Files that cannot be read or parsed are logged and counted as skipped instead of failing the whole run, unless
+// opts.Strict is set.
+// Processing stops with the error of the context once it is done.
+func CountDaysInOffice(ctx context.Context, fileNames []string, opts Options) (Result, error) {
+	if err := opts.validate(); err != nil {
+		return Result{}, err
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency < 1 {
+		concurrency = runtime.NumCPU()
+	}
+
+	result := Result{
+		Days: make(DayMap),
+	}
+
+	result.Stats = processFiles(ctx, fileNames, opts, concurrency, result.Days)
+	if err := ctx.Err(); err != nil {
+		return result, err
+	}
+
+	if err := result.Stats.strictError(opts); err != nil {
+		return result, err
+	}
+
+	result.finish(opts)
+
+	return result, nil
+}
+
+// CountDaysInReader processes a single timeline document, e.g. read from stdin.
+// The name is only used for logging.
+func CountDaysInReader(ctx context.Context, name string, input io.Reader, opts Options) (Result, error) {
+	if err := opts.validate(); err != nil {
+		return Result{}, err
+	}
+
+	result := Result{
+		Days: make(DayMap),
+	}
+
+	stats, err := processInput(name, contextReader{ctx, input}, opts, result.Days)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return result, ctxErr
+	}
+
+	if err != nil {
+		stats.skip(name, err)
+	}
+
+	result.Stats = stats
+	if err := result.Stats.strictError(opts); err != nil {
+		return result, err
+	}
+
+	result.finish(opts)
+
+	return result, nil
+}
+
+// finish removes excluded days and days with fewer matches than required, sums up the time spent and determines the
+// arrival per day, which is only known once all input has been processed
+func (r *Result) finish(opts Options) {
+	dates := r.Days.ToSlice()
+	sort.Strings(dates)
+
+	r.Stats.DurationPerDay = make(map[string]time.Duration, len(dates))
+	r.Stats.ArrivalPerDay = make(map[string]time.Time, len(dates))
+
+	for _, date := range dates {
+		r.Stats.DurationPerDay[date] = mergedDuration(r.Stats.matchesPerDay[date])
+
+		if arrival, ok := earliestStart(r.Stats.matchesPerDay[date]); ok {
+			r.Stats.ArrivalPerDay[date] = arrival
+		}
+
+		if opts.ExcludedDates != nil && opts.ExcludedDates.Contains(date) {
+			log.Debugf("Not counting %s, it is excluded", date)
+			delete(r.Days, date)
+
+			continue
+		}
+
+		if count := len(r.Stats.matchesPerDay[date]); count < opts.MinPointsPerDay {
+			log.Debugf("Not counting %s, only %d of %d required visits matched", date, count, opts.MinPointsPerDay)
+			delete(r.Days, date)
+		}
+	}
+
+	// Near misses are only logged now, as the day may have been counted due to another file or dropped above
+	logNearMisses(r.Stats.nearMisses, r.Days, opts.Parse.RedactCoords)
+}
+
+// processFiles processes the files using a pool of workers.
+// Each worker collects the days into its own map, which are merged once the worker is done.
+// In strict mode no further files are handed out once a file failed.
+func processFiles(ctx context.Context, fileNames []string, opts Options, concurrency int, daysInTheOffice DayMap) Stats {
+	fileNamesChan := make(chan string)
+	results := make(chan Result)
+
+	var wg sync.WaitGroup
+
+	var failed atomic.Bool
+
+	var progressMutex sync.Mutex
+	done := 0
+
+	progress := func() {
+		if opts.Progress == nil {
+			return
+		}
+
+		progressMutex.Lock()
+		defer progressMutex.Unlock()
+
+		done++
+		opts.Progress(done, len(fileNames))
+	}
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			local := Result{
+				Days: make(DayMap),
+			}
+
+			for fileName := range fileNamesChan {
+				fileStats, err := processFile(ctx, fileName, opts, local.Days)
+
+				// Files aborted due to the context are not skipped, processing fails as a whole
+				if ctx.Err() != nil {
+					continue
+				}
+
+				progress()
+
+				if err != nil {
+					local.Stats.skip(fileName, err)
+
+					if local.Stats.strictError(opts) != nil {
+						failed.Store(true)
+					}
+
+					continue
+				}
+
+				local.Stats.add(fileStats)
+			}
+
+			results <- local
+		}()
+	}
+
+	go func() {
+	feed:
+		for _, fileName := range fileNames {
+			if opts.Strict && failed.Load() {
+				break
+			}
+
+			select {
+			case fileNamesChan <- fileName:
+			case <-ctx.Done():
+				break feed
+			}
+		}
+
+		close(fileNamesChan)
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	var stats Stats
+
+	for local := range results {
+		daysInTheOffice.Merge(local.Days)
+
+		stats.add(local.Stats)
+	}
+
+	return stats
+}
+
+func processFile(ctx context.Context, fileName string, opts Options, daysInTheOffice DayMap) (Stats, error) {
+	file, err := os.OpenFile(fileName, os.O_RDONLY, 0)
+	if err != nil {
+		return Stats{}, openError{fmt.Errorf("opening file: %w", err)}
+	}
+	defer file.Close()
+
+	if opts.CacheDir == "" {
+		return processInput(fileName, contextReader{ctx, file}, opts, daysInTheOffice)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return Stats{}, openError{fmt.Errorf("reading file info: %w", err)}
+	}
+
+	c := cache{dir: opts.CacheDir}
+
+	timeline, ok := c.load(fileName, info, opts.Parse)
+	if ok {
+		log.Debug("Using cached timeline", "file", fileName)
+	} else {
+		timeline, err = parseInput(fileName, contextReader{ctx, file}, opts)
+		if err != nil {
+			return Stats{}, err
+		}
+
+		// The cache only speeds up later runs, so this run continues without it
+		if err := c.store(fileName, info, opts.Parse, timeline); err != nil {
+			log.Warn("Could not cache timeline", "file", fileName, "err", err)
+		}
+	}
+
+	return processTimeline(fileName, timeline, opts, daysInTheOffice)
+}
+
+// parseInput decompresses the input if needed and parses it. The name is used to tell GPX files apart.
+func parseInput(name string, file io.Reader, opts Options) (Timeline, error) {
+	logger := log.With("file", name)
+
+	input, err := decompress(file)
+	if err != nil {
+		return Timeline{}, fmt.Errorf("decompressing file: %w", err)
+	}
+
+	var timeline Timeline
+
+	// GPX files are the only input not in one of Google's JSON formats, so they are told apart by their name
+	if isGPX(name) {
+		timeline, err = parseGPX(input, logger)
+	} else {
+		timeline, err = parseTimeline(input, opts.Parse, logger)
+	}
+
+	if err != nil {
+		return Timeline{}, fmt.Errorf("parsing file: %w", err)
+	}
+
+	return timeline, nil
+}
+
+// processInput parses a single timeline document and adds all days with visits to the office to the day map.
+// The name is only used for logging, e.g. the name of the file the input is read from.
+func processInput(name string, file io.Reader, opts Options, daysInTheOffice DayMap) (Stats, error) {
+	timeline, err := parseInput(name, file, opts)
+	if err != nil {
+		return Stats{}, err
+	}
+
+	return processTimeline(name, timeline, opts, daysInTheOffice)
+}
+
+// processTimeline adds all days with visits to the office within the parsed timeline to the day map
+func processTimeline(name string, timeline Timeline, opts Options, daysInTheOffice DayMap) (Stats, error) {
+	logger := log.With("file", name)
+
+	places := timeline.Points
+	if len(places) == 0 {
+		return Stats{}, ErrNoPoints
+	}
+
+	if opts.Parsed != nil {
+		opts.Parsed(name, places)
+	}
+
+	logger.Debug("Parsed file", "format", timeline.Format, "visits", len(places))
+
+	stats := Stats{
+		Formats: map[Format]int{timeline.Format: 1},
+	}
+
+	matcher := opts.matcher()
+	isWorkingDay := opts.isWorkingDay()
+	excluded := RadiusMatcher(opts.ExcludedLocations)
+
+	locations, byLocation := matcher.(locationsMatcher)
+
+	// The distance is tracked for matching visits and only computed for the others in debug output
+	locator, locates := matcher.(nearestLocator)
+	debug := locates && logger.GetLevel() <= log.DebugLevel
+
+	duplicates := 0
+	inRange := 0
+	nullIsland := 0
+
+	for _, place := range places {
+		if !opts.AllowNullIsland && place.Latitude == 0 && place.Longitude == 0 {
+			nullIsland++
+			continue
+		}
+
+		// Some older exports lack the end of a visit, otherwise such visits would end before any start date
+		if place.End.IsZero() {
+			place.End = place.Start
+		}
+
+		// Visits overlapping the range are considered, both ends of the range are inclusive
+		if place.End.Before(opts.StartDate) || (!opts.EndDate.IsZero() && place.Start.After(opts.EndDate)) {
+			// We expect entries to be in sorted order, so we could break here.
+			// But as we do not know for sure we instead go the extra mile.
+			continue
+		}
+
+		inRange++
+		stats.observe(place.Start)
+		stats.cover(interval{Start: opts.localTime(place.Start), End: place.End})
+
+		if opts.SeenVisits != nil && !opts.SeenVisits.Add(newVisitKey(place)) {
+			duplicates++
+			continue
+		}
+
+		// Filters out places that have only been passed by, e.g. when sitting in a train.
+		// Nobody passes by right at the office, so short visits within the core radius are kept.
+		if place.End.Sub(place.Start) < opts.MinDuration && !opts.inCore(place) {
+			continue
+		}
+
+		if place.Confidence != NoConfidence && place.Confidence < opts.MinConfidence {
+			continue
+		}
+
+		if place.Probability != NoProbability && place.Probability < opts.MinProbability {
+			continue
+		}
+
+		// Exclusions are evaluated first, so they win over any overlapping office location
+		if excluded.Matches(place) {
+			logger.Debug("Visit is within an excluded location", "start", place.Start)
+			continue
+		}
+
+		matched := matcher.Matches(place)
+
+		var (
+			nearest  Location
+			distance float64
+			located  bool
+		)
+
+		if locates && (matched || debug) {
+			nearest, distance, located = locator.Nearest(place)
+		}
+
+		if matched {
+			stats.MatchedVisits++
+
+			visit := interval{Start: opts.localTime(place.Start), End: place.End}
+
+			parts := []interval{visit}
+			if opts.CountSpannedDays {
+				parts = visit.splitByDay()
+			}
+
+			var matching []Location
+			if byLocation {
+				matching = locations.Matching(place)
+			}
+
+			if opts.Matched != nil {
+				opts.Matched(parts[0].Start.Format("2006-01-02"), place)
+			}
+
+			for _, part := range parts {
+				date := part.Start.Format("2006-01-02")
+
+				daysInTheOffice.Add(part.Start, isWorkingDay)
+				stats.match(date, part)
+
+				if name := place.Place(); name != "" {
+					stats.visitPlace(date, name)
+				}
+
+				if located {
+					stats.approach(date, distance)
+				}
+
+				for _, loc := range matching {
+					stats.visitLocation(date, loc)
+				}
+			}
+		}
+
+		if debug && located {
+			logger.Debug(fmt.Sprintf("Visit is %.0fm away from %q", distance, nearest.describe(opts.Parse.RedactCoords)), "start", place.Start, "matched", matched)
+
+			if !matched {
+				stats.miss(opts.localTime(place.Start).Format("2006-01-02"), nearMiss{Location: nearest, Distance: distance})
+			}
+		}
+	}
+
+	logger.Debugf("Found %d visits to places in file of which %d have been (partially) within the given time range", len(places), inRange)
+
+	if duplicates > 0 {
+		logger.Debugf("Skipped %d visit(s) already seen in another file", duplicates)
+	}
+
+	if nullIsland > 0 {
+		logger.Debugf("Skipped %d visit(s) at (0,0), which usually lack coordinates", nullIsland)
+	}
+
+	stats.Visits = len(places)
+	stats.VisitsInRange = inRange
+
+	return stats, nil
+}
+
+// nearMiss is the closest visit to any location on a day
+type nearMiss struct {
+	Location Location
+	Distance float64
+}
+
+// logNearMisses logs how close the closest visit got to the office on days which have not been counted
+func logNearMisses(nearMisses map[string]nearMiss, daysInTheOffice DayMap, redact bool) {
+	dates := make([]string, 0, len(nearMisses))
+
+	for date := range nearMisses {
+		if _, ok := daysInTheOffice[date]; !ok {
+			dates = append(dates, date)
+		}
+	}
+
+	sort.Strings(dates)
+
+	for _, date := range dates {
+		miss := nearMisses[date]
+		log.Debugf("No visit to the office on %s, the closest visit was %.0fm away from %q", date, miss.Distance, miss.Location.describe(redact))
+	}
+}
+
+// isGPX reports whether the file name has the extension .gpx, optionally followed by .gz
+func isGPX(name string) bool {
+	return strings.EqualFold(path.Ext(strings.TrimSuffix(name, ".gz")), ".gpx")
+}
+
+// decompress transparently wraps the input in a gzip reader if it starts with the gzip magic bytes
+func decompress(input io.Reader) (io.Reader, error) {
+	buffered := bufio.NewReader(input)
+
+	magic, err := buffered.Peek(2)
+	if err != nil || magic[0] != 0x1f || magic[1] != 0x8b {
+		return buffered, nil
+	}
+
+	reader, err := gzip.NewReader(buffered)
+	if err != nil {
+		return nil, fmt.Errorf("creating gzip reader: %w", err)
+	}
+
+	return reader, nil
+}
@@ -0,0 +1,149 @@
+package office
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/geo"
+)
+
+// testOffice is the location used by the tests, it is the location of the fixtures
+var testOffice = Location{Point: orb.Point{11.5858037, 48.1794935}, Tolerance: 100}
+
+func testOptions() Options {
+	return Options{
+		Locations:   []Location{testOffice},
+		Timezone:    time.UTC,
+		Concurrency: 1,
+	}
+}
+
+func TestCountDaysInOfficeMissingFile(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "missing.json")
+
+	result, err := CountDaysInOffice(context.Background(), []string{fileName}, testOptions())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(result.Days) != 0 {
+		t.Errorf("got %d day(s), want none", len(result.Days))
+	}
+
+	if len(result.Stats.Skipped) != 1 {
+		t.Fatalf("got %d skipped file(s), want 1", len(result.Stats.Skipped))
+	}
+
+	skipped := result.Stats.Skipped[0]
+	if skipped.Name != fileName || skipped.Reason != SkipReasonOpen {
+		t.Errorf("got %s skipped as %q, want %s skipped as %q", skipped.Name, skipped.Reason, fileName, SkipReasonOpen)
+	}
+
+	opts := testOptions()
+	opts.Strict = true
+
+	if _, err := CountDaysInOffice(context.Background(), []string{fileName}, opts); err == nil {
+		t.Error("expected an error in strict mode")
+	}
+}
+
+func TestCountDaysInOfficeMissingEnd(t *testing.T) {
+	opts := testOptions()
+	opts.StartDate = time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	opts.EndDate = time.Date(2024, 3, 31, 23, 59, 59, 0, time.UTC)
+
+	result, err := CountDaysInOffice(context.Background(), []string{filepath.Join("testdata", "legacy_no_end.json")}, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, ok := result.Days["2024-03-06"]; !ok || len(result.Days) != 1 {
+		t.Errorf("got days %v, want only 2024-03-06", result.Days.ToSlice())
+	}
+
+	if result.Stats.VisitsInRange != 1 {
+		t.Errorf("got %d visit(s) in range, want 1", result.Stats.VisitsInRange)
+	}
+}
+
+func TestCountSpannedDays(t *testing.T) {
+	const input = `{"semanticSegments": [{
+		"startTime": "2024-03-04T22:00:00Z",
+		"endTime": "2024-03-05T01:00:00Z",
+		"visit": {"topCandidate": {"placeLocation": {"latLng": "48.1794935°, 11.5858037°"}}}
+	}]}`
+
+	tests := []struct {
+		name             string
+		countSpannedDays bool
+		want             []string
+	}{
+		{name: "start date only", want: []string{"2024-03-04"}},
+		{name: "all spanned days", countSpannedDays: true, want: []string{"2024-03-04", "2024-03-05"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := testOptions()
+			opts.CountSpannedDays = tt.countSpannedDays
+
+			result, err := CountDaysInReader(context.Background(), "input", strings.NewReader(input), opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			got := result.Days.ToSlice()
+			sort.Strings(got)
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got days %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCoreRadius(t *testing.T) {
+	tests := []struct {
+		name     string
+		distance float64
+		duration time.Duration
+		want     bool
+	}{
+		{name: "short visit inside the core radius", distance: 30, duration: 5 * time.Minute, want: true},
+		{name: "long visit inside the core radius", distance: 30, duration: 2 * time.Hour, want: true},
+		{name: "short visit between core radius and tolerance", distance: 150, duration: 5 * time.Minute, want: false},
+		{name: "long visit between core radius and tolerance", distance: 150, duration: 2 * time.Hour, want: true},
+		{name: "long visit outside the tolerance", distance: 250, duration: 2 * time.Hour, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := geo.PointAtBearingAndDistance(testOffice.Point, 90, tt.distance)
+			start := time.Date(2024, 3, 4, 8, 0, 0, 0, time.UTC)
+
+			input := fmt.Sprintf(`{"semanticSegments": [{"startTime": %q, "endTime": %q, "visit": {"topCandidate": {"placeLocation": {"latLng": "%.7f°, %.7f°"}}}}]}`,
+				start.Format(time.RFC3339), start.Add(tt.duration).Format(time.RFC3339), p.Lat(), p.Lon())
+
+			opts := testOptions()
+			opts.Locations = []Location{{Point: testOffice.Point, Tolerance: 200}}
+			opts.CoreRadius = 50
+			opts.MinDuration = 30 * time.Minute
+
+			result, err := CountDaysInReader(context.Background(), "input", strings.NewReader(input), opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if _, got := result.Days["2024-03-04"]; got != tt.want {
+				t.Errorf("got counted %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package office
+
+import "errors"
+
+// ErrNoPoints is returned if a file is in a known format but contains no timeline points, e.g. only activities
+var ErrNoPoints = errors.New("no timeline points found")
+
+// SkipReason tells why a file did not contribute to the result
+type SkipReason string
+
+const (
+	// SkipReasonOpen is used for files that could not be opened
+	SkipReasonOpen SkipReason = "could not be opened"
+	// SkipReasonParse is used for files that could not be decompressed or parsed
+	SkipReasonParse SkipReason = "could not be parsed"
+	// SkipReasonNoTimelineData is used for files in an unknown format, e.g. other files of the Takeout
+	SkipReasonNoTimelineData SkipReason = "contains no timeline data"
+	// SkipReasonNoPoints is used for files in a known format without any timeline points
+	SkipReasonNoPoints SkipReason = "contains no timeline points"
+)
+
+// SkippedFile describes a file that has been skipped and why
+type SkippedFile struct {
+	Name   string
+	Reason SkipReason
+	Err    error
+}
+
+// openError marks errors that occurred while opening a file, all other errors occur while reading it
+type openError struct {
+	err error
+}
+
+func (e openError) Error() string {
+	return e.err.Error()
+}
+
+func (e openError) Unwrap() error {
+	return e.err
+}
+
+func skipReason(err error) SkipReason {
+	var open openError
+
+	switch {
+	case errors.As(err, &open):
+		return SkipReasonOpen
+	case errors.Is(err, ErrNoTimelineData):
+		return SkipReasonNoTimelineData
+	case errors.Is(err, ErrNoPoints):
+		return SkipReasonNoPoints
+	default:
+		return SkipReasonParse
+	}
+}
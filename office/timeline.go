@@ -0,0 +1,645 @@
+package office
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+// ErrNoTimelineData is returned by ParseTimelineInput if the input is valid JSON but contains none of the known formats
+var ErrNoTimelineData = errors.New("no timeline data found, expected timelineObjects, semanticSegments or locations")
+
+// Format is the shape of a timeline export, Google changed it several times
+type Format string
+
+const (
+	// FormatSemanticSegments is the format exported from the device, containing semanticSegments
+	FormatSemanticSegments Format = "semantic-segments"
+	// FormatTimelineObjects is the legacy Takeout format, containing timelineObjects with center coordinates
+	FormatTimelineObjects Format = "timeline-objects"
+	// FormatTimelineObjectsLocation is the legacy Takeout format without center coordinates, the coordinates of the
+	// place are used instead. Google stopped exporting the center coordinates in February 2024.
+	FormatTimelineObjectsLocation Format = "timeline-objects-location"
+	// FormatSegmentsArray is a bare array of semantic segments, as exported by the iOS app or re-exported by other tools
+	FormatSegmentsArray Format = "segments-array"
+	// FormatRecords is the raw location history of Records.json, containing locations without any semantic information
+	FormatRecords Format = "records"
+)
+
+// Timeline holds the points of a parsed timeline export
+type Timeline struct {
+	Points []TimelinePoint
+	Format Format
+}
+
+// ParseOptions controls which entries of the input are returned by ParseTimelineInput
+type ParseOptions struct {
+	// IncludeActivities additionally returns the start and end points of movements between places
+	IncludeActivities bool
+	// RedactCoords leaves out or rounds coordinates in log output, so logs can be shared without revealing places.
+	// It also applies to the log output while processing the points, the points themselves keep full precision.
+	RedactCoords bool
+	// PreferLocation uses the coordinates of the place instead of the center of the visit in the legacy format, even if
+	// the latter are present
+	PreferLocation bool
+}
+
+// coordsError returns the error for log output, which is left out if coordinates are redacted as it contains them
+func (o ParseOptions) coordsError(err error) any {
+	if o.RedactCoords {
+		return "(redacted)"
+	}
+
+	return err
+}
+
+// ParseTimelineInput parses a timeline export in any of the known formats, skipped entries are logged to the default
+// logger
+func ParseTimelineInput(input io.Reader, opts ParseOptions) (Timeline, error) {
+	return parseTimeline(input, opts, log.Default())
+}
+
+// parseTimeline parses a timeline export, skipped entries are logged to the logger, e.g. one with the file name
+func parseTimeline(input io.Reader, opts ParseOptions, logger *log.Logger) (Timeline, error) {
+	// The input is decoded token by token, so only a single entry has to be held in memory at once.
+	// Exports spanning years can be several gigabytes large.
+	decoder := json.NewDecoder(input)
+
+	token, err := decoder.Token()
+	if err != nil {
+		return Timeline{}, fmt.Errorf("decoding JSON: %w", err)
+	}
+
+	if delim, ok := token.(json.Delim); ok && delim == '[' {
+		var result []TimelinePoint
+
+		err := decodeElements(decoder, func(entry semanticSegment) {
+			result = append(result, entry.points(opts, logger)...)
+		})
+		if err != nil {
+			return Timeline{}, fmt.Errorf("decoding array of segments: %w", err)
+		}
+
+		return Timeline{Points: result, Format: FormatSegmentsArray}, nil
+	}
+
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return Timeline{}, fmt.Errorf("decoding JSON: expected an object with timelineObjects, semanticSegments or locations, or an array of segments, got %v", token)
+	}
+
+	var (
+		semanticResult, legacyResult, recordsResult []TimelinePoint
+		hasSemantic, hasLegacy, hasRecords          bool
+		usesLocation                                bool
+	)
+
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return Timeline{}, fmt.Errorf("decoding JSON: %w", err)
+		}
+
+		// Keys of an object are always strings
+		key := token.(string)
+
+		switch key {
+		case "semanticSegments":
+			hasSemantic, err = decodeArray(decoder, func(entry semanticSegment) {
+				semanticResult = append(semanticResult, entry.points(opts, logger)...)
+			})
+		case "timelineObjects":
+			hasLegacy, err = decodeArray(decoder, func(entry timelineObject) {
+				usesLocation = usesLocation || entry.usesLocation()
+				legacyResult = append(legacyResult, entry.points(opts, logger)...)
+			})
+		case "locations":
+			hasRecords, err = decodeArray(decoder, func(entry record) {
+				if point, ok := entry.point(opts, logger); ok {
+					recordsResult = append(recordsResult, point)
+				}
+			})
+		default:
+			err = skipValue(decoder)
+		}
+
+		if err != nil {
+			return Timeline{}, fmt.Errorf("decoding %s: %w", key, err)
+		}
+	}
+
+	if !hasSemantic && !hasLegacy && !hasRecords {
+		return Timeline{}, ErrNoTimelineData
+	}
+
+	// Merged exports may contain several formats, e.g. the newer semantic location history exported from the local
+	// device along with the legacy format or the raw location history. The points of all of them are used, the format
+	// is reported as the most recent one.
+	points := append(append(semanticResult, legacyResult...), recordsResult...)
+
+	var format Format
+
+	switch {
+	case hasSemantic:
+		format = FormatSemanticSegments
+	case hasLegacy && usesLocation:
+		format = FormatTimelineObjectsLocation
+	case hasLegacy:
+		format = FormatTimelineObjects
+	default:
+		format = FormatRecords
+	}
+
+	sections := 0
+
+	for _, has := range []bool{hasSemantic, hasLegacy, hasRecords} {
+		if has {
+			sections++
+		}
+	}
+
+	if sections > 1 {
+		logger.Debug("Found several formats, using the points of all of them", "semanticSegments", hasSemantic, "timelineObjects", hasLegacy, "locations", hasRecords)
+	}
+
+	return Timeline{Points: points, Format: format}, nil
+}
+
+// decodeArray decodes the elements of a JSON array one by one and passes them to handle.
+// It reports false if the value is null instead of an array.
+func decodeArray[T any](decoder *json.Decoder, handle func(T)) (bool, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return false, err
+	}
+
+	if token == nil {
+		return false, nil
+	}
+
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return false, fmt.Errorf("expected an array, got %v", token)
+	}
+
+	if err := decodeElements(decoder, handle); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// decodeElements decodes the remaining elements of an array whose opening bracket has already been consumed
+func decodeElements[T any](decoder *json.Decoder, handle func(T)) error {
+	for decoder.More() {
+		var entry T
+
+		if err := decoder.Decode(&entry); err != nil {
+			return err
+		}
+
+		handle(entry)
+	}
+
+	// Consume the closing bracket
+	_, err := decoder.Token()
+
+	return err
+}
+
+// skipValue consumes the next value, without holding it in memory
+func skipValue(decoder *json.Decoder) error {
+	depth := 0
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+func (entry semanticSegment) points(opts ParseOptions, logger *log.Logger) []TimelinePoint {
+	var result []TimelinePoint
+
+	for _, point := range entry.TimelinePath {
+		lat, long, err := ParsePoint(point.Point)
+		if err != nil {
+			logger.Warn("Skipping point with invalid coordinates", "err", opts.coordsError(err))
+			continue
+		}
+
+		result = append(result, TimelinePoint{
+			Latitude:    lat,
+			Longitude:   long,
+			Start:       entry.StartTime.Time,
+			End:         entry.EndTime.Time,
+			Confidence:  NoConfidence,
+			Probability: NoProbability,
+		})
+	}
+
+	// Newer exports contain visits instead of a timeline path
+	if entry.Visit != nil {
+		lat, long, err := ParsePoint(string(entry.Visit.TopCandidate.PlaceLocation))
+		if err != nil {
+			logger.Warn("Skipping visit with invalid coordinates", "err", opts.coordsError(err))
+		} else {
+			result = append(result, TimelinePoint{
+				Latitude:    lat,
+				Longitude:   long,
+				Start:       entry.StartTime.Time,
+				End:         entry.EndTime.Time,
+				Confidence:  NoConfidence,
+				Probability: entry.Visit.TopCandidate.Probability.value(),
+			})
+		}
+	}
+
+	if opts.IncludeActivities && entry.Activity != nil {
+		startLat, startLong, startErr := ParsePoint(string(entry.Activity.Start))
+		endLat, endLong, endErr := ParsePoint(string(entry.Activity.End))
+
+		if err := errors.Join(startErr, endErr); err != nil {
+			logger.Warn("Skipping activity with invalid coordinates", "err", opts.coordsError(err))
+		} else {
+			result = append(result, activityPoints(startLat, startLong, endLat, endLong, entry.StartTime.Time, entry.EndTime.Time)...)
+		}
+	}
+
+	return result
+}
+
+func (entry timelineObject) points(opts ParseOptions, logger *log.Logger) []TimelinePoint {
+	var result []TimelinePoint
+
+	if opts.IncludeActivities && entry.ActivitySegment != nil {
+		activity := entry.ActivitySegment
+
+		startLat, startLong, startOK := fromE7(activity.StartLocation.LatitudeE7, activity.StartLocation.LongitudeE7, opts, logger)
+		endLat, endLong, endOK := fromE7(activity.EndLocation.LatitudeE7, activity.EndLocation.LongitudeE7, opts, logger)
+
+		if startOK && endOK {
+			result = append(result, activityPoints(startLat, startLong, endLat, endLong, activity.Duration.Start.Time, activity.Duration.End.Time)...)
+		}
+	}
+
+	// Entries that are neither place visits nor activity segments are ignored
+	if entry.PlaceVisit == nil {
+		return result
+	}
+
+	// Google removed these two fields at some point, so we simply take the second best option.
+	// See below.
+	hasLocation := entry.PlaceVisit.Location.LatitudeE7 != 0 || entry.PlaceVisit.Location.LongitudeE7 != 0
+	if entry.usesLocation() || (opts.PreferLocation && hasLocation) {
+		entry.PlaceVisit.CenterLatE7 = entry.PlaceVisit.Location.LatitudeE7
+		entry.PlaceVisit.CenterLngE7 = entry.PlaceVisit.Location.LongitudeE7
+	}
+
+	place := entry.PlaceVisit
+
+	lat, long, ok := fromE7(place.CenterLatE7, place.CenterLngE7, opts, logger)
+	if !ok {
+		return result
+	}
+
+	return append(result, TimelinePoint{
+		Latitude:    lat,
+		Longitude:   long,
+		Start:       place.Duration.Start.Time,
+		End:         place.Duration.End.Time,
+		Confidence:  place.VisitConfidence,
+		Probability: NoProbability,
+		Name:        place.Location.Name,
+		Address:     place.Location.Address,
+	})
+}
+
+// usesLocation reports whether the entry is a place visit without center coordinates
+func (entry timelineObject) usesLocation() bool {
+	return entry.PlaceVisit != nil && (entry.PlaceVisit.CenterLatE7 == 0 || entry.PlaceVisit.CenterLngE7 == 0)
+}
+
+// point returns the raw location as a point without duration
+func (entry record) point(opts ParseOptions, logger *log.Logger) (TimelinePoint, bool) {
+	timestamp := entry.Timestamp.Time
+
+	// Older exports contain the milliseconds since epoch instead of a timestamp
+	if timestamp.IsZero() && entry.TimestampMs != "" {
+		ms, err := strconv.ParseInt(entry.TimestampMs, 10, 64)
+		if err != nil {
+			logger.Warn("Skipping record with invalid timestamp", "err", err)
+			return TimelinePoint{}, false
+		}
+
+		timestamp = time.UnixMilli(ms).UTC()
+	}
+
+	if timestamp.IsZero() {
+		logger.Warn("Skipping record without timestamp")
+		return TimelinePoint{}, false
+	}
+
+	lat, long, ok := fromE7(entry.LatitudeE7, entry.LongitudeE7, opts, logger)
+	if !ok {
+		return TimelinePoint{}, false
+	}
+
+	return TimelinePoint{
+		Latitude:    lat,
+		Longitude:   long,
+		Start:       timestamp,
+		End:         timestamp,
+		Confidence:  NoConfidence,
+		Probability: NoProbability,
+	}, true
+}
+
+// fromE7 converts coordinates given as integers scaled by 1e7 to degrees, ok is false if they are out of range.
+// This does not detect coordinates scaled by 1e6, those are ten times too small and usually still in range.
+func fromE7(latE7, longE7 int, opts ParseOptions, logger *log.Logger) (lat, long float64, ok bool) {
+	lat = float64(latE7) / 1e7
+	long = float64(longE7) / 1e7
+
+	if math.Abs(lat) > 90 || math.Abs(long) > 180 {
+		if opts.RedactCoords {
+			logger.Warn("Skipping point with implausible coordinates, expected them to be scaled by 1e7")
+		} else {
+			logger.Warn("Skipping point with implausible coordinates, expected them to be scaled by 1e7", "latitudeE7", latE7, "longitudeE7", longE7)
+		}
+
+		return 0, 0, false
+	}
+
+	return lat, long, true
+}
+
+// activityPoints returns the points at which a movement between places started and ended
+func activityPoints(startLat, startLong, endLat, endLong float64, start, end time.Time) []TimelinePoint {
+	return []TimelinePoint{
+		{
+			Latitude:    startLat,
+			Longitude:   startLong,
+			Start:       start,
+			End:         start,
+			Confidence:  NoConfidence,
+			Probability: NoProbability,
+			Activity:    true,
+		},
+		{
+			Latitude:    endLat,
+			Longitude:   endLong,
+			Start:       end,
+			End:         end,
+			Confidence:  NoConfidence,
+			Probability: NoProbability,
+			Activity:    true,
+		},
+	}
+}
+
+// ParsePoint parses a latitude and longitude as used by the newer export format and shown by Google Maps
+func ParsePoint(value string) (float64, float64, error) {
+	// "51.6503959°, 5.0492413°", but also "51.6503959°,5.0492413°" or "geo:51.6503959,5.0492413"
+	trimmed := strings.TrimPrefix(strings.TrimSpace(value), "geo:")
+
+	coords := strings.Split(strings.ReplaceAll(trimmed, "°", ""), ",")
+	if len(coords) != 2 {
+		return 0, 0, fmt.Errorf("expected two coordinates in %q", value)
+	}
+
+	lat, err := strconv.ParseFloat(strings.TrimSpace(coords[0]), 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("parsing latitude of %q: %w", value, err)
+	}
+
+	long, err := strconv.ParseFloat(strings.TrimSpace(coords[1]), 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("parsing longitude of %q: %w", value, err)
+	}
+
+	return lat, long, nil
+}
+
+// Place returns the name and address of the visited place joined by a comma, it is empty if neither is known
+func (p TimelinePoint) Place() string {
+	var parts []string
+
+	for _, part := range []string{p.Name, p.Address} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// NoConfidence is used as confidence for points of formats that do not provide one
+const NoConfidence = -1
+
+// NoProbability is used as probability for points without one, only visits of the newer format provide one
+const NoProbability = -1
+
+// TimelinePoint is a visit to a place, or a point passed on the way, found in the timeline
+type TimelinePoint struct {
+	Latitude  float64
+	Longitude float64
+
+	Start time.Time
+	End   time.Time
+
+	// Confidence is the visit confidence in the range [0, 100] or NoConfidence
+	Confidence int
+	// Probability is the probability of the visited place in the range [0, 1] or NoProbability
+	Probability float64
+	// Activity is set for the start and end points of movements between places
+	Activity bool
+
+	// Name and Address of the visited place, only available in the legacy format
+	Name    string
+	Address string
+}
+
+type timelineObject struct {
+	PlaceVisit      *timelineVisitedPlace    `json:"placeVisit"`
+	ActivitySegment *timelineActivitySegment `json:"activitySegment"`
+}
+
+type timelineVisitedPlace struct {
+	Location struct {
+		LatitudeE7  int    `json:"latitudeE7"`
+		LongitudeE7 int    `json:"longitudeE7"`
+		Address     string `json:"address"`
+		Name        string `json:"name"`
+	} `json:"location"`
+	Duration struct {
+		Start timestamp `json:"startTimestamp"`
+		End   timestamp `json:"endTimestamp"`
+	} `json:"duration"`
+	VisitConfidence int `json:"visitConfidence"`
+	// It seems like Google removed these two fields on the 7th of February 2024 as they don't show up in records
+	// after this date.
+	CenterLatE7 int `json:"centerLatE7"`
+	CenterLngE7 int `json:"centerLngE7"`
+}
+
+type timelineActivitySegment struct {
+	StartLocation struct {
+		LatitudeE7  int `json:"latitudeE7"`
+		LongitudeE7 int `json:"longitudeE7"`
+	} `json:"startLocation"`
+	EndLocation struct {
+		LatitudeE7  int `json:"latitudeE7"`
+		LongitudeE7 int `json:"longitudeE7"`
+	} `json:"endLocation"`
+	Duration struct {
+		Start timestamp `json:"startTimestamp"`
+		End   timestamp `json:"endTimestamp"`
+	} `json:"duration"`
+}
+
+type semanticSegment struct {
+	StartTime    timestamp `json:"startTime"`
+	EndTime      timestamp `json:"endTime"`
+	TimelinePath []struct {
+		Point string    `json:"point"`
+		Time  timestamp `json:"time"`
+	} `json:"timelinePath"`
+	Visit *struct {
+		TopCandidate struct {
+			PlaceLocation latLng      `json:"placeLocation"`
+			Probability   probability `json:"probability"`
+		} `json:"topCandidate"`
+	} `json:"visit"`
+	Activity *struct {
+		Start latLng `json:"start"`
+		End   latLng `json:"end"`
+	} `json:"activity"`
+}
+
+// latLng holds coordinates like "51.6503959°, 5.0492413°". Exports from Android wrap them in an object with a latLng
+// field, while exports from iOS contain the bare string like "geo:51.6503959,5.0492413".
+type latLng string
+
+func (l *latLng) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err == nil {
+		*l = latLng(value)
+		return nil
+	}
+
+	var object struct {
+		LatLng string `json:"latLng"`
+	}
+
+	if err := json.Unmarshal(data, &object); err != nil {
+		return err
+	}
+
+	*l = latLng(object.LatLng)
+
+	return nil
+}
+
+// timestampLayouts are the layouts of timestamps found in exports, tried in order.
+// RFC3339 also accepts fractional seconds, the others cover offsets without colon and timestamps without offset.
+var timestampLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05.999999999Z0700",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+}
+
+// timestamp is a time which accepts the various timestamp layouts found in exports, timestamps without offset are
+// taken as UTC
+type timestamp struct {
+	time.Time
+}
+
+func (t *timestamp) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("expected timestamp as string: %w", err)
+	}
+
+	// Missing timestamps are handled by the callers, e.g. visits without end
+	if value == "" {
+		t.Time = time.Time{}
+		return nil
+	}
+
+	for _, layout := range timestampLayouts {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			t.Time = parsed
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown timestamp layout of %q", value)
+}
+
+// probability is the probability of a place candidate, exports from the device contain a number while the iOS app
+// exports a string
+type probability struct {
+	Value float64
+	Valid bool
+}
+
+func (p *probability) UnmarshalJSON(data []byte) error {
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	switch v := value.(type) {
+	case nil:
+		*p = probability{}
+	case float64:
+		*p = probability{Value: v, Valid: true}
+	case string:
+		parsed, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return fmt.Errorf("parsing probability: %w", err)
+		}
+
+		*p = probability{Value: parsed, Valid: true}
+	default:
+		return fmt.Errorf("expected probability as number or string, got %v", value)
+	}
+
+	return nil
+}
+
+// value returns the probability or NoProbability if it is missing
+func (p probability) value() float64 {
+	if !p.Valid {
+		return NoProbability
+	}
+
+	return p.Value
+}
+
+// record is an entry of the raw location history in Records.json
+type record struct {
+	LatitudeE7  int       `json:"latitudeE7"`
+	LongitudeE7 int       `json:"longitudeE7"`
+	Timestamp   timestamp `json:"timestamp"`
+	TimestampMs string    `json:"timestampMs"`
+}
@@ -0,0 +1,204 @@
+package office
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/paulmach/orb"
+)
+
+// benchmarkEntries is the number of visits in the generated fixtures, roughly a year of a busy timeline
+const benchmarkEntries = 20000
+
+var benchmarkStart = time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+
+// generateLegacy returns an export in the legacy format with n place visits, each followed by an activity segment
+func generateLegacy(n int) []byte {
+	var buf bytes.Buffer
+
+	buf.WriteString(`{"timelineObjects":[`)
+
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+
+		start := benchmarkStart.Add(time.Duration(i) * time.Hour)
+		end := start.Add(45 * time.Minute)
+		lat, lng := 481794935+i%1000, 115858037-i%1000
+
+		fmt.Fprintf(&buf, `{"placeVisit":{"location":{"latitudeE7":%d,"longitudeE7":%d,"address":"1 Main St","name":"Acme HQ"},`+
+			`"duration":{"startTimestamp":%q,"endTimestamp":%q},"visitConfidence":90,"centerLatE7":%d,"centerLngE7":%d}},`,
+			lat, lng, start.Format(time.RFC3339), end.Format(time.RFC3339), lat, lng)
+		fmt.Fprintf(&buf, `{"activitySegment":{"startLocation":{"latitudeE7":%d,"longitudeE7":%d},"endLocation":{"latitudeE7":%d,"longitudeE7":%d},`+
+			`"duration":{"startTimestamp":%q,"endTimestamp":%q}}}`,
+			lat, lng, lat+1000, lng+1000, end.Format(time.RFC3339), end.Add(15*time.Minute).Format(time.RFC3339))
+	}
+
+	buf.WriteString(`]}`)
+
+	return buf.Bytes()
+}
+
+// generateSemantic returns an export in the newer format with n visits, each followed by a timeline path
+func generateSemantic(n int) []byte {
+	var buf bytes.Buffer
+
+	buf.WriteString(`{"semanticSegments":[`)
+
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+
+		start := benchmarkStart.Add(time.Duration(i) * time.Hour)
+		end := start.Add(45 * time.Minute)
+		lat, lng := 48.1794935+float64(i%1000)/1e7, 11.5858037-float64(i%1000)/1e7
+
+		fmt.Fprintf(&buf, `{"startTime":%q,"endTime":%q,"visit":{"topCandidate":{"placeLocation":{"latLng":"%.7f°, %.7f°"},"probability":0.9}}},`,
+			start.Format(time.RFC3339), end.Format(time.RFC3339), lat, lng)
+		fmt.Fprintf(&buf, `{"startTime":%q,"endTime":%q,"timelinePath":[{"point":"%.7f°, %.7f°","time":%q},{"point":"%.7f°, %.7f°","time":%q}]}`,
+			end.Format(time.RFC3339), end.Add(15*time.Minute).Format(time.RFC3339),
+			lat, lng, end.Format(time.RFC3339), lat+0.001, lng+0.001, end.Add(15*time.Minute).Format(time.RFC3339))
+	}
+
+	buf.WriteString(`]}`)
+
+	return buf.Bytes()
+}
+
+var benchmarkFormats = []struct {
+	name     string
+	generate func(int) []byte
+}{
+	{"legacy", generateLegacy},
+	{"semantic", generateSemantic},
+}
+
+func BenchmarkParseTimelineInput(b *testing.B) {
+	for _, format := range benchmarkFormats {
+		input := format.generate(benchmarkEntries)
+
+		b.Run(format.name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(input)))
+
+			for i := 0; i < b.N; i++ {
+				if _, err := ParseTimelineInput(bytes.NewReader(input), ParseOptions{}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkProcessFile(b *testing.B) {
+	opts := Options{
+		Locations: []Location{{Point: orb.Point{11.5858037, 48.1794935}, Tolerance: 100}},
+		Timezone:  time.UTC,
+	}
+
+	for _, format := range benchmarkFormats {
+		input := format.generate(benchmarkEntries)
+
+		fileName := filepath.Join(b.TempDir(), format.name+".json")
+		if err := os.WriteFile(fileName, input, 0o644); err != nil {
+			b.Fatal(err)
+		}
+
+		b.Run(format.name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(input)))
+
+			for i := 0; i < b.N; i++ {
+				if _, err := processFile(context.Background(), fileName, opts, make(DayMap)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// parseWholeDocument decodes the whole document at once, like the parser did before decoding it entry by entry.
+// It is only kept as a baseline for BenchmarkParseMemory, measure is called while the document is still in memory.
+func parseWholeDocument(input io.Reader, measure func()) ([]TimelinePoint, error) {
+	var document struct {
+		TimelineObjects []timelineObject `json:"timelineObjects"`
+	}
+
+	if err := json.NewDecoder(input).Decode(&document); err != nil {
+		return nil, err
+	}
+
+	var result []TimelinePoint
+
+	for _, entry := range document.TimelineObjects {
+		result = append(result, entry.points(ParseOptions{}, log.Default())...)
+	}
+
+	measure()
+	runtime.KeepAlive(document)
+
+	return result, nil
+}
+
+// BenchmarkParseMemory compares the heap in use once a large export has been parsed, reported as live-B/op, with
+// decoding the whole document at once
+func BenchmarkParseMemory(b *testing.B) {
+	input := generateLegacy(benchmarkEntries)
+
+	// liveHeap returns the bytes on the heap which are still referenced
+	liveHeap := func() int64 {
+		var stats runtime.MemStats
+
+		runtime.GC()
+		runtime.ReadMemStats(&stats)
+
+		return int64(stats.HeapAlloc)
+	}
+
+	parsers := []struct {
+		name  string
+		parse func(input io.Reader, measure func()) ([]TimelinePoint, error)
+	}{
+		{"streaming", func(input io.Reader, measure func()) ([]TimelinePoint, error) {
+			timeline, err := ParseTimelineInput(input, ParseOptions{})
+			measure()
+
+			return timeline.Points, err
+		}},
+		{"whole-document", parseWholeDocument},
+	}
+
+	for _, parser := range parsers {
+		b.Run(parser.name, func(b *testing.B) {
+			b.ReportAllocs()
+
+			var live int64
+
+			for i := 0; i < b.N; i++ {
+				before := liveHeap()
+
+				points, err := parser.parse(bytes.NewReader(input), func() {
+					live += liveHeap() - before
+				})
+				if err != nil {
+					b.Fatal(err)
+				}
+
+				runtime.KeepAlive(points)
+			}
+
+			b.ReportMetric(float64(live)/float64(b.N), "live-B/op")
+		})
+	}
+}
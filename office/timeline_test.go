@@ -0,0 +1,381 @@
+package office
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// sameTime reports whether both times are the same instant with the same offset, the location itself may differ as
+// time.Parse uses the local timezone if it has the offset of a timestamp
+func sameTime(a, b time.Time) bool {
+	_, offsetA := a.Zone()
+	_, offsetB := b.Zone()
+
+	return a.Equal(b) && offsetA == offsetB
+}
+
+// assertPoints fails the test unless both lists contain the same points in the same order
+func assertPoints(t *testing.T, got, want []TimelinePoint) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("got %d point(s), want %d: %+v", len(got), len(want), got)
+	}
+
+	for i := range want {
+		g, w := got[i], want[i]
+
+		if !sameTime(g.Start, w.Start) || !sameTime(g.End, w.End) {
+			t.Errorf("point %d: got %s - %s, want %s - %s", i, g.Start, g.End, w.Start, w.End)
+		}
+
+		g.Start, g.End, w.Start, w.End = time.Time{}, time.Time{}, time.Time{}, time.Time{}
+
+		if !reflect.DeepEqual(g, w) {
+			t.Errorf("point %d: got %+v, want %+v", i, g, w)
+		}
+	}
+}
+
+func parseFixture(t *testing.T, name string, opts ParseOptions) (Timeline, error) {
+	t.Helper()
+
+	file, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	return ParseTimelineInput(file, opts)
+}
+
+func TestParseTimelineInput(t *testing.T) {
+	tests := []struct {
+		name       string
+		file       string
+		opts       ParseOptions
+		wantFormat Format
+		want       []TimelinePoint
+		wantErr    bool
+	}{
+		{
+			name:       "legacy with center coordinates",
+			file:       "legacy_center.json",
+			wantFormat: FormatTimelineObjects,
+			want: []TimelinePoint{
+				{
+					Latitude:    48.1794935,
+					Longitude:   11.5858037,
+					Start:       time.Date(2024, 1, 15, 8, 2, 11, 123000000, time.UTC),
+					End:         time.Date(2024, 1, 15, 17, 30, 0, 0, time.UTC),
+					Confidence:  93,
+					Probability: NoProbability,
+					Name:        "Acme HQ",
+					Address:     "1 Main St",
+				},
+				{
+					Latitude:    48.1501234,
+					Longitude:   11.5504321,
+					Start:       time.Date(2024, 1, 15, 18, 5, 0, 0, time.UTC),
+					End:         time.Date(2024, 1, 16, 7, 40, 0, 0, time.UTC),
+					Confidence:  71,
+					Probability: NoProbability,
+					Name:        "Home",
+				},
+			},
+		},
+		{
+			name:       "legacy with center coordinates preferring the location",
+			file:       "legacy_center.json",
+			opts:       ParseOptions{PreferLocation: true},
+			wantFormat: FormatTimelineObjects,
+			want: []TimelinePoint{
+				{
+					Latitude:    48.179,
+					Longitude:   11.585,
+					Start:       time.Date(2024, 1, 15, 8, 2, 11, 123000000, time.UTC),
+					End:         time.Date(2024, 1, 15, 17, 30, 0, 0, time.UTC),
+					Confidence:  93,
+					Probability: NoProbability,
+					Name:        "Acme HQ",
+					Address:     "1 Main St",
+				},
+				{
+					Latitude:    48.15,
+					Longitude:   11.55,
+					Start:       time.Date(2024, 1, 15, 18, 5, 0, 0, time.UTC),
+					End:         time.Date(2024, 1, 16, 7, 40, 0, 0, time.UTC),
+					Confidence:  71,
+					Probability: NoProbability,
+					Name:        "Home",
+				},
+			},
+		},
+		{
+			name:       "legacy falling back to the location",
+			file:       "legacy_location.json",
+			wantFormat: FormatTimelineObjectsLocation,
+			want: []TimelinePoint{
+				{
+					Latitude:    48.1794935,
+					Longitude:   11.5858037,
+					Start:       time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC),
+					End:         time.Date(2024, 3, 1, 16, 45, 30, 0, time.UTC),
+					Confidence:  88,
+					Probability: NoProbability,
+					Name:        "Acme HQ",
+					Address:     "1 Main St",
+				},
+			},
+		},
+		{
+			name:       "semantic segments with timeline path",
+			file:       "semantic_path.json",
+			wantFormat: FormatSemanticSegments,
+			want: []TimelinePoint{
+				{
+					Latitude:    48.1794935,
+					Longitude:   11.5858037,
+					Start:       time.Date(2024, 3, 4, 7, 0, 0, 0, time.UTC),
+					End:         time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC),
+					Confidence:  NoConfidence,
+					Probability: NoProbability,
+				},
+				{
+					Latitude:    48.15,
+					Longitude:   11.55,
+					Start:       time.Date(2024, 3, 4, 7, 0, 0, 0, time.UTC),
+					End:         time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC),
+					Confidence:  NoConfidence,
+					Probability: NoProbability,
+				},
+			},
+		},
+		{
+			name:       "records with timestamp and timestampMs",
+			file:       "records.json",
+			wantFormat: FormatRecords,
+			want: []TimelinePoint{
+				{
+					Latitude:    48.1794935,
+					Longitude:   11.5858037,
+					Start:       time.Date(2024, 3, 5, 8, 15, 30, 250000000, time.UTC),
+					End:         time.Date(2024, 3, 5, 8, 15, 30, 250000000, time.UTC),
+					Confidence:  NoConfidence,
+					Probability: NoProbability,
+				},
+				{
+					Latitude:    48.15,
+					Longitude:   11.55,
+					Start:       time.Date(2024, 3, 5, 17, 30, 0, 0, time.UTC),
+					End:         time.Date(2024, 3, 5, 17, 30, 0, 0, time.UTC),
+					Confidence:  NoConfidence,
+					Probability: NoProbability,
+				},
+			},
+		},
+		{
+			name:       "merged export with all formats",
+			file:       "merged.json",
+			wantFormat: FormatSemanticSegments,
+			want: []TimelinePoint{
+				{
+					Latitude:    48.1794935,
+					Longitude:   11.5858037,
+					Start:       time.Date(2024, 3, 7, 8, 0, 0, 0, time.UTC),
+					End:         time.Date(2024, 3, 7, 12, 0, 0, 0, time.UTC),
+					Confidence:  NoConfidence,
+					Probability: 0.87,
+				},
+				{
+					Latitude:    48.1794935,
+					Longitude:   11.5858037,
+					Start:       time.Date(2024, 2, 1, 8, 0, 0, 0, time.UTC),
+					End:         time.Date(2024, 2, 1, 17, 0, 0, 0, time.UTC),
+					Confidence:  80,
+					Probability: NoProbability,
+					Name:        "Acme HQ",
+				},
+				{
+					Latitude:    48.1794935,
+					Longitude:   11.5858037,
+					Start:       time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC),
+					End:         time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC),
+					Confidence:  NoConfidence,
+					Probability: NoProbability,
+				},
+			},
+		},
+		{
+			name:       "legacy with out-of-range coordinates",
+			file:       "legacy_out_of_range.json",
+			wantFormat: FormatTimelineObjectsLocation,
+			want: []TimelinePoint{
+				{
+					Latitude:    48.15,
+					Longitude:   11.55,
+					Start:       time.Date(2024, 1, 15, 18, 5, 0, 0, time.UTC),
+					End:         time.Date(2024, 1, 16, 7, 40, 0, 0, time.UTC),
+					Confidence:  71,
+					Probability: NoProbability,
+					Name:        "Home",
+				},
+			},
+		},
+		{
+			name:    "malformed JSON",
+			file:    "malformed.json",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timeline, err := parseFixture(t, tt.file, tt.opts)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %d point(s)", len(timeline.Points))
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if timeline.Format != tt.wantFormat {
+				t.Errorf("got format %s, want %s", timeline.Format, tt.wantFormat)
+			}
+
+			assertPoints(t, timeline.Points, tt.want)
+		})
+	}
+}
+
+func TestParsePoint(t *testing.T) {
+	tests := []struct {
+		value   string
+		lat     float64
+		long    float64
+		wantErr bool
+	}{
+		{value: "51.6503959°, 5.0492413°", lat: 51.6503959, long: 5.0492413},
+		{value: "51.6503959°,5.0492413°", lat: 51.6503959, long: 5.0492413},
+		{value: "  51.6503959 ° ,   5.0492413 °  ", lat: 51.6503959, long: 5.0492413},
+		{value: "51.6503959, 5.0492413", lat: 51.6503959, long: 5.0492413},
+		{value: "geo:51.6503959,5.0492413", lat: 51.6503959, long: 5.0492413},
+		{value: " geo:51.6503959, 5.0492413 ", lat: 51.6503959, long: 5.0492413},
+		{value: "-33.8567844°, -70.6653423°", lat: -33.8567844, long: -70.6653423},
+		{value: "geo:-33.8567844,151.2152967", lat: -33.8567844, long: 151.2152967},
+		{value: "", wantErr: true},
+		{value: "51.6503959°", wantErr: true},
+		{value: "51.6503959°, 5.0492413°, 12", wantErr: true},
+		{value: "north°, 5.0492413°", wantErr: true},
+		{value: "51.6503959°, east°", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			lat, long, err := ParsePoint(tt.value)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %g, %g", lat, long)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if lat != tt.lat || long != tt.long {
+				t.Errorf("got %g, %g, want %g, %g", lat, long, tt.lat, tt.long)
+			}
+		})
+	}
+}
+
+func TestTimestampUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		want    time.Time
+		wantErr bool
+	}{
+		{
+			name: "RFC3339",
+			json: `"2021-05-01T08:00:00Z"`,
+			want: time.Date(2021, 5, 1, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "RFC3339 with milliseconds",
+			json: `"2021-05-01T08:00:00.000Z"`,
+			want: time.Date(2021, 5, 1, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "RFC3339 with nanoseconds and offset",
+			json: `"2021-05-01T08:00:00.123456789+02:00"`,
+			want: time.Date(2021, 5, 1, 8, 0, 0, 123456789, time.FixedZone("", 2*60*60)),
+		},
+		{
+			name: "offset without colon",
+			json: `"2021-05-01T08:00:00.500+0200"`,
+			want: time.Date(2021, 5, 1, 8, 0, 0, 500000000, time.FixedZone("", 2*60*60)),
+		},
+		{
+			name: "without offset",
+			json: `"2021-05-01T08:00:00.250"`,
+			want: time.Date(2021, 5, 1, 8, 0, 0, 250000000, time.UTC),
+		},
+		{
+			name: "space instead of T",
+			json: `"2021-05-01 08:00:00-04:00"`,
+			want: time.Date(2021, 5, 1, 8, 0, 0, 0, time.FixedZone("", -4*60*60)),
+		},
+		{
+			name: "empty",
+			json: `""`,
+		},
+		{
+			name:    "unknown layout",
+			json:    `"01/05/2021 08:00"`,
+			wantErr: true,
+		},
+		{
+			name:    "not a string",
+			json:    `1619856000000`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got timestamp
+
+			err := got.UnmarshalJSON([]byte(tt.json))
+
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %s", got.Time)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !sameTime(got.Time, tt.want) {
+				t.Errorf("got %s, want %s", got.Time, tt.want)
+			}
+		})
+	}
+}
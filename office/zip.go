@@ -0,0 +1,108 @@
+package office
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// CountDaysInZip reads all timeline files from a Google Takeout archive without extracting it.
+// Processing stops with the error of the context once it is done.
+func CountDaysInZip(ctx context.Context, zipName string, opts Options) (Result, error) {
+	if err := opts.validate(); err != nil {
+		return Result{}, err
+	}
+
+	archive, err := zip.OpenReader(zipName)
+	if err != nil {
+		return Result{}, fmt.Errorf("opening zip archive %s: %w", zipName, err)
+	}
+	defer archive.Close()
+
+	result := Result{
+		Days: make(DayMap),
+	}
+
+	var entries []*zip.File
+
+	for _, entry := range archive.File {
+		if isTimelineEntry(entry.Name) {
+			entries = append(entries, entry)
+		}
+	}
+
+	for i, entry := range entries {
+		fileName := zipName + ":" + entry.Name
+
+		fileStats, err := processZipEntry(ctx, fileName, entry, opts, result.Days)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return result, ctxErr
+		}
+
+		if opts.Progress != nil {
+			opts.Progress(i+1, len(entries))
+		}
+
+		if err != nil {
+			result.Stats.skip(fileName, err)
+
+			if err := result.Stats.strictError(opts); err != nil {
+				return result, err
+			}
+
+			continue
+		}
+
+		result.Stats.add(fileStats)
+	}
+
+	result.finish(opts)
+
+	return result, nil
+}
+
+func processZipEntry(ctx context.Context, fileName string, entry *zip.File, opts Options, daysInTheOffice DayMap) (Stats, error) {
+	file, err := entry.Open()
+	if err != nil {
+		return Stats{}, openError{fmt.Errorf("opening file: %w", err)}
+	}
+	defer file.Close()
+
+	return processInput(fileName, contextReader{ctx, file}, opts, daysInTheOffice)
+}
+
+// ListZipEntries returns the names of all timeline files within a Google Takeout archive
+func ListZipEntries(zipName string) ([]string, error) {
+	archive, err := zip.OpenReader(zipName)
+	if err != nil {
+		return nil, fmt.Errorf("opening zip archive %s: %w", zipName, err)
+	}
+	defer archive.Close()
+
+	var list []string
+
+	for _, entry := range archive.File {
+		if isTimelineEntry(entry.Name) {
+			list = append(list, zipName+":"+entry.Name)
+		}
+	}
+
+	return list, nil
+}
+
+// isTimelineEntry reports whether the archive entry is part of the Semantic Location History,
+// e.g. "Takeout/Location History (Timeline)/Semantic Location History/2023/2023_JANUARY.json",
+// or the raw location history "Takeout/Location History (Timeline)/Records.json"
+func isTimelineEntry(name string) bool {
+	if path.Base(name) == "Records.json" {
+		return true
+	}
+
+	if !strings.HasSuffix(name, ".json") && !strings.HasSuffix(name, ".json.gz") {
+		return false
+	}
+
+	return strings.Contains(path.Dir(name), "Semantic Location History")
+}
@@ -0,0 +1,298 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	mapset "github.com/deckarep/golang-set/v2"
+	"github.com/florianloch/days-in-office/office"
+)
+
+// dateFormats are named presets for -date-format
+var dateFormats = map[string]string{
+	"iso": "2006-01-02",
+	"eu":  "02/01/2006",
+	"us":  "01/02/2006",
+}
+
+// parseDateFormat resolves a preset or validates a Go layout by formatting and parsing a reference date
+func parseDateFormat(value string) (string, error) {
+	if layout, ok := dateFormats[value]; ok {
+		return layout, nil
+	}
+
+	reference := time.Date(2021, time.November, 23, 0, 0, 0, 0, time.UTC)
+
+	parsed, err := time.Parse(value, reference.Format(value))
+	if err != nil {
+		return "", fmt.Errorf("invalid layout %q: %w", value, err)
+	}
+
+	if parsed.Year() != reference.Year() || parsed.YearDay() != reference.YearDay() {
+		return "", fmt.Errorf("layout %q does not contain year, month and day", value)
+	}
+
+	return value, nil
+}
+
+// formatDate formats a key of office.DayMap using the given layout
+func formatDate(date, layout string) string {
+	t, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return date
+	}
+
+	return t.Format(layout)
+}
+
+// printDates writes one line per day in ascending order, annotating non-working days and the matched places if known.
+// If distancePerDay is set, the distance of the closest matching visit is printed as well, which helps to judge days
+// close to the tolerance.
+func printDates(w io.Writer, daysInTheOffice office.DayMap, cal office.Calendar, placesPerDay map[string]mapset.Set[string], distancePerDay map[string]float64, unit distanceUnit, layout string) {
+	list := daysInTheOffice.ToSlice()
+
+	sort.Strings(list)
+
+	for _, date := range list {
+		fmt.Fprint(w, formatDate(date, layout))
+
+		var labels []string
+
+		if cal.IsHoliday(date) {
+			labels = append(labels, "holiday")
+		} else if !daysInTheOffice[date] {
+			labels = append(labels, "weekend")
+		}
+
+		if distance, ok := distancePerDay[date]; ok {
+			if len(labels) == 0 {
+				labels = append(labels, "working")
+			}
+
+			labels = append(labels, unit.format(distance))
+		}
+
+		if len(labels) > 0 {
+			fmt.Fprintf(w, " (%s)", strings.Join(labels, ", "))
+		}
+
+		if places, ok := placesPerDay[date]; ok {
+			names := places.ToSlice()
+			sort.Strings(names)
+
+			for i, name := range names {
+				names[i] = strconv.Quote(name)
+			}
+
+			fmt.Fprintf(w, " — %s", strings.Join(names, "; "))
+		}
+
+		fmt.Fprint(w, "\n")
+	}
+}
+
+type jsonResult struct {
+	TotalDays   int        `json:"totalDays"`
+	WorkingDays int        `json:"workingDays"`
+	WeekendDays int        `json:"weekendDays"`
+	Dates       []jsonDate `json:"dates"`
+}
+
+type jsonDate struct {
+	Date       string `json:"date"`
+	WorkingDay bool   `json:"workingDay"`
+}
+
+// writeJSON writes the days as a single JSON object, suitable for scripting
+func writeJSON(w io.Writer, daysInTheOffice office.DayMap, layout string) error {
+	list := daysInTheOffice.ToSlice()
+
+	sort.Strings(list)
+
+	workingDays := daysInTheOffice.CountWorkingDays()
+
+	result := jsonResult{
+		TotalDays:   len(daysInTheOffice),
+		WorkingDays: workingDays,
+		WeekendDays: len(daysInTheOffice) - workingDays,
+		Dates:       make([]jsonDate, 0, len(list)),
+	}
+
+	for _, date := range list {
+		result.Dates = append(result.Dates, jsonDate{
+			Date:       formatDate(date, layout),
+			WorkingDay: daysInTheOffice[date],
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(result); err != nil {
+		return fmt.Errorf("encoding JSON: %w", err)
+	}
+
+	return nil
+}
+
+// csvHeader holds the columns of the records returned by csvRecords
+var csvHeader = []string{"date", "working_day", "weekday"}
+
+// writeCSV writes one row per day in ascending order with the columns date, working_day and weekday
+func writeCSV(w io.Writer, daysInTheOffice office.DayMap, header bool, layout string) error {
+	records, err := csvRecords(daysInTheOffice, layout)
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+
+	if header {
+		if err := writer.Write(csvHeader); err != nil {
+			return fmt.Errorf("writing CSV header: %w", err)
+		}
+	}
+
+	if err := writer.WriteAll(records); err != nil {
+		return fmt.Errorf("writing CSV records: %w", err)
+	}
+
+	return nil
+}
+
+// csvRecords returns one record per day in ascending order with the columns of csvHeader
+func csvRecords(daysInTheOffice office.DayMap, layout string) ([][]string, error) {
+	list := daysInTheOffice.ToSlice()
+
+	sort.Strings(list)
+
+	records := make([][]string, 0, len(list))
+
+	for _, date := range list {
+		t, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			return nil, fmt.Errorf("parsing date %s: %w", date, err)
+		}
+
+		records = append(records, []string{t.Format(layout), strconv.FormatBool(daysInTheOffice[date]), t.Weekday().String()})
+	}
+
+	return records, nil
+}
+
+// writePersonsCSV writes the days of all persons into a single CSV, the rows are prefixed with the name of the person
+func writePersonsCSV(w io.Writer, people []person, header bool, layout string) error {
+	writer := csv.NewWriter(w)
+
+	if header {
+		if err := writer.Write(append([]string{"person"}, csvHeader...)); err != nil {
+			return fmt.Errorf("writing CSV header: %w", err)
+		}
+	}
+
+	for _, p := range people {
+		records, err := csvRecords(p.Days, layout)
+		if err != nil {
+			return err
+		}
+
+		for _, record := range records {
+			if err := writer.Write(append([]string{p.Name}, record...)); err != nil {
+				return fmt.Errorf("writing CSV record: %w", err)
+			}
+		}
+	}
+
+	writer.Flush()
+
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("writing CSV: %w", err)
+	}
+
+	return nil
+}
+
+// writeICS writes an iCalendar file with an all-day event per day
+func writeICS(w io.Writer, daysInTheOffice office.DayMap, cal office.Calendar) error {
+	list := daysInTheOffice.ToSlice()
+
+	sort.Strings(list)
+
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//days-in-office//EN",
+		"CALSCALE:GREGORIAN",
+	}
+
+	stamp := time.Now().UTC().Format("20060102T150405Z")
+
+	for _, date := range list {
+		t, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			return fmt.Errorf("parsing date %s: %w", date, err)
+		}
+
+		summary := "In office"
+		if cal.IsHoliday(date) {
+			summary = "In office (holiday)"
+		} else if !daysInTheOffice[date] {
+			summary = "In office (weekend)"
+		}
+
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			"UID:"+date+"@days-in-office",
+			"DTSTAMP:"+stamp,
+			"DTSTART;VALUE=DATE:"+t.Format("20060102"),
+			"DTEND;VALUE=DATE:"+t.AddDate(0, 0, 1).Format("20060102"),
+			"SUMMARY:"+summary,
+			"TRANSP:TRANSPARENT",
+			"END:VEVENT",
+		)
+	}
+
+	lines = append(lines, "END:VCALENDAR")
+
+	// iCalendar requires CRLF line endings
+	for _, line := range lines {
+		if _, err := io.WriteString(w, line+"\r\n"); err != nil {
+			return fmt.Errorf("writing iCalendar: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// writeMarkdown writes a Markdown table with the number of days per period and a total row
+func writeMarkdown(w io.Writer, groups []group, period string) error {
+	lines := []string{
+		fmt.Sprintf("| %s | Days | Working days |", period),
+		"| --- | ---: | ---: |",
+	}
+
+	total := group{Key: "**Total**"}
+
+	for _, g := range groups {
+		lines = append(lines, fmt.Sprintf("| %s | %d | %d |", g.Key, g.Days, g.WorkingDays))
+
+		total.Days += g.Days
+		total.WorkingDays += g.WorkingDays
+	}
+
+	lines = append(lines, fmt.Sprintf("| %s | %d | %d |", total.Key, total.Days, total.WorkingDays))
+
+	for _, line := range lines {
+		if _, err := io.WriteString(w, line+"\n"); err != nil {
+			return fmt.Errorf("writing Markdown: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	mapset "github.com/deckarep/golang-set/v2"
+	"github.com/florianloch/days-in-office/office"
+)
+
+// person holds the days in the office of a single person in -per-person mode
+type person struct {
+	Name string
+	Days office.DayMap
+}
+
+// countPerPerson counts the days in the office for each top-level subdirectory of inputDir, which is named after the
+// person whose export it contains
+func countPerPerson(ctx context.Context, inputDir string, patterns, ignore []string, followSymlinks bool, opts office.Options, dedupe bool, only string) ([]person, error) {
+	fileNames, err := listFilesRecursively(inputDir, patterns, ignore, followSymlinks)
+	if err != nil {
+		return nil, fmt.Errorf("could not list files: %w", err)
+	}
+
+	filesPerPerson := groupByPerson(inputDir, fileNames)
+
+	names := make([]string, 0, len(filesPerPerson))
+
+	for name := range filesPerPerson {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	people := make([]person, 0, len(names))
+
+	for _, name := range names {
+		// Visits of different people are not duplicates of each other
+		if dedupe {
+			opts.SeenVisits = mapset.NewSet[office.VisitKey]()
+		}
+
+		result, err := office.CountDaysInOffice(ctx, filesPerPerson[name], opts)
+		if err != nil {
+			return nil, err
+		}
+
+		reportSkippedFiles(result.Stats.Skipped)
+		filterDays(result.Days, only)
+
+		log.Infof("%s has been in the office on %d day(s) of which %d have been working days.", name, len(result.Days), result.Days.CountWorkingDays())
+
+		people = append(people, person{
+			Name: name,
+			Days: result.Days,
+		})
+	}
+
+	return people, nil
+}
+
+// groupByPerson groups the files by the top-level subdirectory of inputDir they are in.
+// Files directly within inputDir do not belong to anyone and are skipped.
+func groupByPerson(inputDir string, fileNames []string) map[string][]string {
+	filesPerPerson := make(map[string][]string)
+
+	for _, fileName := range fileNames {
+		rel, err := filepath.Rel(inputDir, fileName)
+		if err != nil {
+			log.Warn("Skipping file outside of the input directory", "file", fileName)
+			continue
+		}
+
+		name, _, found := strings.Cut(filepath.ToSlash(rel), "/")
+		if !found {
+			log.Warn("Skipping file not belonging to a person, expected one subdirectory per person", "file", fileName)
+			continue
+		}
+
+		filesPerPerson[name] = append(filesPerPerson[name], fileName)
+	}
+
+	return filesPerPerson
+}
+
+func printPersons(w io.Writer, people []person) {
+	for _, p := range people {
+		fmt.Fprintf(w, "%s: %d day(s), %d working day(s)\n", p.Name, len(p.Days), p.Days.CountWorkingDays())
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	mapset "github.com/deckarep/golang-set/v2"
+	"github.com/florianloch/days-in-office/office"
+)
+
+// availableStats lists the reports that can be requested via -stats
+var availableStats = mapset.NewThreadUnsafeSet("weekday", "streak", "hours", "locations", "coverage")
+
+// parseStats parses a comma-separated list of reports like "weekday"
+func parseStats(value string) (mapset.Set[string], error) {
+	stats := mapset.NewThreadUnsafeSet[string]()
+
+	if value == "" {
+		return stats, nil
+	}
+
+	for _, part := range strings.Split(value, ",") {
+		name := strings.TrimSpace(part)
+
+		if !availableStats.Contains(name) {
+			return nil, fmt.Errorf("unknown statistic %q", name)
+		}
+
+		stats.Add(name)
+	}
+
+	return stats, nil
+}
+
+// printWeekdayStats prints on how many days the office was visited per weekday, starting with Monday
+func printWeekdayStats(w io.Writer, daysInTheOffice office.DayMap) error {
+	var counts [7]int
+
+	for date := range daysInTheOffice {
+		t, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			return fmt.Errorf("parsing date %s: %w", date, err)
+		}
+
+		counts[t.Weekday()]++
+	}
+
+	for i := 1; i <= 7; i++ {
+		weekday := time.Weekday(i % 7)
+
+		percentage := 0.0
+		if len(daysInTheOffice) > 0 {
+			percentage = float64(counts[weekday]) / float64(len(daysInTheOffice)) * 100
+		}
+
+		fmt.Fprintf(w, "%s: %d (%.0f%%)\n", weekday.String()[:3], counts[weekday], percentage)
+	}
+
+	return nil
+}
+
+// streak is a run of consecutive working days, non-working days in between do not interrupt it
+type streak struct {
+	Length int
+	Start  time.Time
+	End    time.Time
+}
+
+// longestStreaks walks the working days and returns the longest run of days in the office and the longest run of
+// days not in the office
+func longestStreaks(daysInTheOffice office.DayMap, workingDays []time.Time) (present, absent streak) {
+	var current streak
+
+	currentInOffice := false
+
+	for i, day := range workingDays {
+		_, inOffice := daysInTheOffice[day.Format("2006-01-02")]
+
+		if i == 0 || inOffice != currentInOffice {
+			current = streak{Start: day}
+			currentInOffice = inOffice
+		}
+
+		current.Length++
+		current.End = day
+
+		if inOffice && current.Length > present.Length {
+			present = current
+		} else if !inOffice && current.Length > absent.Length {
+			absent = current
+		}
+	}
+
+	return present, absent
+}
+
+func printStreakStats(w io.Writer, daysInTheOffice office.DayMap, workingDays []time.Time, layout string) {
+	present, absent := longestStreaks(daysInTheOffice, workingDays)
+
+	printStreak := func(name string, s streak) {
+		if s.Length == 0 {
+			fmt.Fprintf(w, "%s: none\n", name)
+			return
+		}
+
+		fmt.Fprintf(w, "%s: %d working day(s) from %s to %s\n", name, s.Length, s.Start.Format(layout), s.End.Format(layout))
+	}
+
+	printStreak("Longest streak in the office", present)
+	printStreak("Longest gap without office visit", absent)
+}
+
+// maxDurationPerDay caps the time counted per day, so visits spanning the night do not inflate the hours
+const maxDurationPerDay = 12 * time.Hour
+
+// printHoursStats prints the time spent in the office based on the durations of the matching visits
+func printHoursStats(w io.Writer, daysInTheOffice office.DayMap, durationPerDay map[string]time.Duration) {
+	var total time.Duration
+
+	for date := range daysInTheOffice {
+		duration := durationPerDay[date]
+		if duration > maxDurationPerDay {
+			duration = maxDurationPerDay
+		}
+
+		total += duration
+	}
+
+	average := 0.0
+	if len(daysInTheOffice) > 0 {
+		average = total.Hours() / float64(len(daysInTheOffice))
+	}
+
+	fmt.Fprintf(w, "Hours in the office: %.1f in total, %.1f on average per office day\n", total.Hours(), average)
+}
+
+// printLocationStats prints the days on which more than one location has been visited along with the locations
+func printLocationStats(w io.Writer, daysInTheOffice office.DayMap, locationsPerDay map[string]mapset.Set[string], layout string) {
+	dates := daysInTheOffice.ToSlice()
+	sort.Strings(dates)
+
+	var lines []string
+
+	for _, date := range dates {
+		locations, ok := locationsPerDay[date]
+		if !ok || locations.Cardinality() < 2 {
+			continue
+		}
+
+		names := locations.ToSlice()
+		sort.Strings(names)
+
+		lines = append(lines, fmt.Sprintf("  %s: %s", formatDate(date, layout), strings.Join(names, ", ")))
+	}
+
+	fmt.Fprintf(w, "Days with multiple locations: %d\n", len(lines))
+
+	for _, line := range lines {
+		fmt.Fprintln(w, line)
+	}
+}
+
+// printCoverageStats prints the working days without any location data, on which it is unknown whether the office
+// has been visited
+func printCoverageStats(w io.Writer, datesWithData mapset.Set[string], workingDays []time.Time, layout string) {
+	var missing []string
+
+	for _, day := range workingDays {
+		date := day.Format("2006-01-02")
+
+		if datesWithData == nil || !datesWithData.Contains(date) {
+			missing = append(missing, formatDate(date, layout))
+		}
+	}
+
+	fmt.Fprintf(w, "Working days without location data: %d of %d\n", len(missing), len(workingDays))
+
+	for _, date := range missing {
+		fmt.Fprintf(w, "  %s\n", date)
+	}
+}
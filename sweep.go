@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	mapset "github.com/deckarep/golang-set/v2"
+	"github.com/florianloch/days-in-office/office"
+)
+
+// sweepResult holds the number of days in the office when using the tolerance for all locations
+type sweepResult struct {
+	Tolerance   float64
+	Days        int
+	WorkingDays int
+}
+
+// sweep counts the days in the office once per tolerance, overriding the tolerance of all locations
+func sweep(count func(office.Options) (office.Result, error), opts office.Options, tolerances []float64, dedupe bool) ([]sweepResult, error) {
+	results := make([]sweepResult, 0, len(tolerances))
+
+	for _, tolerance := range tolerances {
+		locations := make([]office.Location, len(opts.Locations))
+
+		for i, loc := range opts.Locations {
+			loc.Tolerance = tolerance
+			locations[i] = loc
+		}
+
+		runOpts := opts
+		runOpts.Locations = locations
+
+		// Every run has to see all visits again
+		if dedupe {
+			runOpts.SeenVisits = mapset.NewSet[office.VisitKey]()
+		}
+
+		result, err := count(runOpts)
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, sweepResult{
+			Tolerance:   tolerance,
+			Days:        len(result.Days),
+			WorkingDays: result.Days.CountWorkingDays(),
+		})
+	}
+
+	return results, nil
+}
+
+// parseDistances parses a comma-separated list of distances like "250,500m,1km"
+func parseDistances(value string) ([]float64, error) {
+	var distances []float64
+
+	for _, part := range strings.Split(value, ",") {
+		distance, err := parseDistance(part)
+		if err != nil {
+			return nil, err
+		}
+
+		if distance <= 0 {
+			return nil, fmt.Errorf("distance %q has to be greater than 0", part)
+		}
+
+		distances = append(distances, distance)
+	}
+
+	return distances, nil
+}
+
+func printSweep(w io.Writer, results []sweepResult) {
+	for _, r := range results {
+		fmt.Fprintf(w, "%gm: %d day(s), %d working day(s)\n", r.Tolerance, r.Days, r.WorkingDays)
+	}
+}
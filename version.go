@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"runtime/debug"
+)
+
+// Set at build time, e.g. via -ldflags "-X main.version=v1.2.3 -X main.commit=abc1234 -X main.date=2024-03-01"
+var (
+	version = ""
+	commit  = ""
+	date    = ""
+)
+
+// printVersion prints the version, commit and build date, values not set at build time are taken from the build info
+// embedded by the Go toolchain if available
+func printVersion(w io.Writer) {
+	v, c, d := version, commit, date
+
+	if info, ok := debug.ReadBuildInfo(); ok {
+		if v == "" && info.Main.Version != "(devel)" {
+			v = info.Main.Version
+		}
+
+		for _, setting := range info.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if c == "" {
+					c = setting.Value
+				}
+			case "vcs.time":
+				if d == "" {
+					d = setting.Value
+				}
+			}
+		}
+	}
+
+	fmt.Fprintf(w, "days-in-office %s (commit %s, built %s)\n", orUnknown(v), orUnknown(c), orUnknown(d))
+}
+
+func orUnknown(value string) string {
+	if value == "" {
+		return "unknown"
+	}
+
+	return value
+}